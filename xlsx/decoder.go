@@ -0,0 +1,100 @@
+package xlsx
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	x "github.com/tealeg/xlsx"
+)
+
+// Decoder decode sheet data rows one by one, like json.Decoder
+type Decoder struct {
+	reader   *Reader   // owner reader
+	sheet    string    // sheet name
+	header   *x.Row    // header row
+	data     []*x.Row  // data rows
+	next     int       // next data row index
+	mappings *sync.Map // column mapping cache
+	filler   *fillDown // fill down state
+	err      error     // pending error
+}
+
+// NewDecoder create new decoder of reader's sheet
+func NewDecoder(reader *Reader, sheetName string) *Decoder {
+
+	decoder := &Decoder{
+		reader:   reader,
+		sheet:    sheetName,
+		mappings: &sync.Map{},
+	}
+
+	sheet, err := reader.lookupSheet(sheetName)
+
+	if err != nil {
+		decoder.err = err
+		return decoder
+	}
+
+	decoder.header, decoder.data, decoder.err = reader.splitRows(sheet)
+
+	if decoder.header != nil {
+		decoder.filler = reader.newFillDown(decoder.header)
+	}
+
+	return decoder
+}
+
+// More check if there is another data row to decode, or a pending error
+func (decoder *Decoder) More() bool {
+
+	if decoder.err != nil {
+		return true
+	}
+
+	if decoder.reader.SkipBlankRows {
+		for decoder.next < len(decoder.data) && isBlankRow(decoder.data[decoder.next]) {
+			decoder.next++
+		}
+	}
+
+	return decoder.next < len(decoder.data)
+}
+
+// DecodeContext decode like Decode, return ctx.Err() if the context is done
+func (decoder *Decoder) DecodeContext(ctx context.Context, val interface{}) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return decoder.Decode(val)
+}
+
+// Decode read current data row into val and advance to next row,
+// return io.EOF if there is no more row
+func (decoder *Decoder) Decode(val interface{}) error {
+
+	if decoder.err != nil {
+		err := decoder.err
+		decoder.err = nil
+		decoder.data = nil
+		return err
+	}
+
+	if !decoder.More() {
+		return io.EOF
+	}
+
+	if decoder.reader.file == nil {
+		return ErrClosed
+	}
+
+	id := decoder.next
+
+	decoder.next++
+
+	row := decoder.filler.fill(decoder.data[id])
+
+	return decoder.reader.newRowReader(decoder.sheet, decoder.header, row, id, decoder.reader.dataRow(decoder.sheet, id), decoder.mappings).Read(val)
+}
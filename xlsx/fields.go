@@ -0,0 +1,586 @@
+package xlsx
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// columnFields get the exported fields of struct type which can be mapped to columns,
+// the fields of embedded structs are flattened like the promoted fields of go
+func columnFields(structType reflect.Type) (fields []reflect.StructField) {
+
+	for _, field := range reflect.VisibleFields(structType) {
+
+		if field.Anonymous {
+			fieldType := field.Type
+
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+
+			if tag, _ := fieldTag(field); tag == "" && fieldType.Kind() == reflect.Struct {
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return
+}
+
+// foldName normalize column or field name for case insensitive matching,
+// the case and insignificant spaces/underscores are ignored
+func foldName(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "").Replace(name))
+}
+
+// foldedFields get the folded name to field name index of struct type,
+// the index is built once per struct type and cached in cache
+func foldedFields(cache *sync.Map, structType reflect.Type) map[string]string {
+
+	if index, ok := cache.Load(structType); ok {
+		return index.(map[string]string)
+	}
+
+	index := make(map[string]string)
+
+	for _, field := range columnFields(structType) {
+
+		name, _ := fieldTag(field)
+
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if _, ok := index[foldName(name)]; !ok {
+			index[foldName(name)] = field.Name
+		}
+	}
+
+	cached, _ := cache.LoadOrStore(structType, index)
+
+	return cached.(map[string]string)
+}
+
+// column the resolved mapping of header column
+type column struct {
+	index       int          // column index
+	name        string       // resolved column name
+	key         string       // "Sheet.Column" key of Unmarshalers and Pattern
+	unmarshaler UnmarshalF   // column keyed unmarshaler
+	field       []int        // struct field index, nil if not found
+	err         error        // mapping error returned for every cell, e.g. unexported field
+	group       *groupColumn // numbered column of group, nil if not grouped
+	opts        tagOptions   // xlsx tag options of field
+	numeric     bool         // the value is the stored number of numeric cell, which is not normalized by number
+}
+
+// fieldMapping the resolved columns of header for one struct type
+type fieldMapping struct {
+	columns    []*column   // mapped columns in header order
+	unknown    []string    // header columns which can't be mapped
+	missing    []string    // struct fields which are not mapped by any column
+	required   []string    // missing fields tagged with "required", which are always errors
+	duplicates []string    // header columns resolved to the name of previous column
+	groups     [][]int     // index of group slice fields, which are reset before read
+	sheets     [][]int     // index of fields tagged with "-,sheet"
+	rownums    [][]int     // index of fields tagged with "-,rownum", set to the 1-based excel row number
+	keys       []*keyField // fields tagged with "-,key:A+B"
+}
+
+// keyField the field tagged with "-,key:A+B", which is set to the composite key of
+// the trimmed column values joined by the "sep:" option, default "|". the columns
+// are matched by the header name or the resolved column name like RowReader.Cell
+type keyField struct {
+	field   []int    // struct field index
+	name    string   // struct field name
+	columns []string // source columns
+	sep     string   // separator of column values
+}
+
+// mapping get the column mapping of struct type, which is resolved once
+// per sheet and struct type and shared by all row readers of the sheet
+func (reader *RowReader) mapping(structType reflect.Type) *fieldMapping {
+
+	if reader.mappings == nil {
+		reader.mappings = &sync.Map{}
+	}
+
+	if mapping, ok := reader.mappings.Load(structType); ok {
+		return mapping.(*fieldMapping)
+	}
+
+	mapping := &fieldMapping{}
+
+	if reader.headerless {
+		reader.indexMapping(structType, mapping)
+	}
+
+	seen := make(map[string]bool)
+
+	for i, cell := range reader.header.Cells {
+
+		name, ok := reader.fieldName(structType, cell.Value)
+
+		if !ok {
+			continue
+		}
+
+		if name != "" && seen[name] {
+			reader.W("duplicate column(%s) of sheet %s, the first one wins", cell.Value, reader.Sheet)
+			mapping.duplicates = append(mapping.duplicates, cell.Value)
+			continue
+		}
+
+		seen[name] = true
+
+		col := &column{
+			index: i,
+			name:  name,
+			key:   reader.Sheet + "." + name,
+		}
+
+		if f, ok := reader.unmarshalers[col.key]; ok {
+			col.unmarshaler = f
+		} else if field, ok := structType.FieldByName(name); ok && !field.IsExported() {
+			col.err = &ErrUnmarshalField{Key: cell.Value, Type: structType, Field: field}
+		} else if ok {
+			col.field = field.Index
+			_, col.opts = fieldTag(field)
+		} else if index, field, ok := reader.fieldPath(structType, name); ok {
+			col.field = index
+			_, col.opts = fieldTag(field)
+		} else if group, field, ok := reader.groupColumn(structType, name); ok {
+			col.group = group
+			_, col.opts = fieldTag(field)
+			mapping.addGroup(group.field)
+		} else {
+			mapping.unknown = append(mapping.unknown, cell.Value)
+		}
+
+		// like encoding/json, the nil embedded pointer to unexported struct can't be allocated
+		if embedded, ok := unexportedEmbedded(structType, col.field); ok && col.err == nil {
+			col.err = &ErrUnmarshalField{Key: cell.Value, Type: structType, Field: embedded}
+		}
+
+		mapping.columns = append(mapping.columns, col)
+	}
+
+	for _, field := range columnFields(structType) {
+
+		name, opts := fieldTag(field)
+
+		if name != "-" {
+			continue
+		}
+
+		if opts.Has("sheet") && field.Type.Kind() == reflect.String {
+			mapping.sheets = append(mapping.sheets, field.Index)
+		}
+
+		if opts.Has("rownum") && isIntKind(field.Type.Kind()) {
+			mapping.rownums = append(mapping.rownums, field.Index)
+		}
+
+		if key := opts.Get("key"); key != "" && field.Type.Kind() == reflect.String {
+
+			sep := opts.Get("sep")
+
+			if sep == "" {
+				sep = "|"
+			}
+
+			mapping.keys = append(mapping.keys, &keyField{field: field.Index, name: field.Name, columns: strings.Split(key, "+"), sep: sep})
+		}
+	}
+
+	mapping.missing, mapping.required = missingFields(structType, mapping)
+
+	cached, _ := reader.mappings.LoadOrStore(structType, mapping)
+
+	return cached.(*fieldMapping)
+}
+
+// missingFields get the name of struct fields which are not mapped by any column and the
+// required ones of them, the nested struct and group fields are mapped if any of their
+// columns is mapped
+func missingFields(structType reflect.Type, mapping *fieldMapping) (missing, required []string) {
+
+	var paths [][]int
+
+	for _, col := range mapping.columns {
+		switch {
+		case col.field != nil:
+			paths = append(paths, col.field)
+		case col.group != nil:
+			paths = append(paths, col.group.field)
+		case col.unmarshaler != nil:
+			if field, ok := structType.FieldByName(col.name); ok {
+				paths = append(paths, field.Index)
+			}
+		}
+	}
+
+	for _, field := range columnFields(structType) {
+
+		if name, _ := fieldTag(field); name == "-" {
+			continue
+		}
+
+		mapped := false
+
+		for _, path := range paths {
+			if len(path) >= len(field.Index) && reflect.DeepEqual(path[:len(field.Index)], field.Index) {
+				mapped = true
+				break
+			}
+		}
+
+		if mapped {
+			continue
+		}
+
+		missing = append(missing, field.Name)
+
+		if _, opts := fieldTag(field); opts.Has("required") {
+			required = append(required, field.Name)
+		}
+	}
+
+	return
+}
+
+// indexMapping map the fields tagged with "col:N" to columns by index for headerless sheet,
+// the fields without col tag are skipped
+func (reader *RowReader) indexMapping(structType reflect.Type, mapping *fieldMapping) {
+
+	for _, field := range columnFields(structType) {
+
+		index, ok := fieldColumn(field)
+
+		if !ok {
+			continue
+		}
+
+		col := &column{
+			index: index,
+			name:  field.Name,
+			key:   reader.Sheet + "." + field.Name,
+		}
+
+		if f, ok := reader.unmarshalers[col.key]; ok {
+			col.unmarshaler = f
+		} else if embedded, ok := unexportedEmbedded(structType, field.Index); ok {
+			col.err = &ErrUnmarshalField{Key: field.Name, Type: structType, Field: embedded}
+		} else {
+			col.field = field.Index
+			_, col.opts = fieldTag(field)
+		}
+
+		mapping.columns = append(mapping.columns, col)
+	}
+}
+
+// groupColumn the numbered column of group, the slice of struct field tagged with "group"
+// option collects the columns named by element field and the 1-based element number,
+// e.g. field Items []Item tagged with `xlsx:",group"` reads columns "Name1", "Price1", "Name2", "Price2"
+// into Items[0].Name, Items[0].Price, Items[1].Name, Items[1].Price
+type groupColumn struct {
+	field []int // index of slice field
+	index int   // zero based element index
+	elem  []int // index of element field
+}
+
+// groupColumn resolve the column name like "Price2" to the element field of group
+func (reader *RowReader) groupColumn(structType reflect.Type, name string) (*groupColumn, reflect.StructField, bool) {
+
+	prefix := strings.TrimRightFunc(name, unicode.IsDigit)
+
+	n, err := strconv.Atoi(name[len(prefix):])
+
+	if prefix == "" || err != nil || n < 1 {
+		return nil, reflect.StructField{}, false
+	}
+
+	for _, field := range columnFields(structType) {
+
+		if _, opts := fieldTag(field); !opts.Has("group") || field.Type.Kind() != reflect.Slice {
+			continue
+		}
+
+		elemType := field.Type.Elem()
+
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+
+		if elemType.Kind() != reflect.Struct {
+			continue
+		}
+
+		if elem, ok := reader.structField(elemType, prefix); ok {
+			return &groupColumn{field: field.Index, index: n - 1, elem: elem.Index}, elem, true
+		}
+	}
+
+	return nil, reflect.StructField{}, false
+}
+
+// elemField get the element field of group in v, the slice is grown and the nil
+// element pointer is allocated if required
+func (group *groupColumn) elemField(v reflect.Value) reflect.Value {
+
+	slice := fieldByIndex(v, group.field)
+
+	for slice.Len() <= group.index {
+		slice.Set(reflect.Append(slice, reflect.Zero(slice.Type().Elem())))
+	}
+
+	elem := slice.Index(group.index)
+
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+
+		elem = elem.Elem()
+	}
+
+	return fieldByIndex(elem, group.elem)
+}
+
+// addGroup add the group slice field if not added
+func (mapping *fieldMapping) addGroup(index []int) {
+
+	for _, group := range mapping.groups {
+		if reflect.DeepEqual(group, index) {
+			return
+		}
+	}
+
+	mapping.groups = append(mapping.groups, index)
+}
+
+// subexpFields map pattern's sub expressions to field indexes of struct type.
+// the named groups are matched to fields by tag or field name, the unnamed patterns
+// fall back to positional assignment. -1 means the group is not mapped
+func subexpFields(pattern *regexp.Regexp, structType reflect.Type) []int {
+
+	names := pattern.SubexpNames()[1:]
+
+	fields := make([]int, len(names))
+
+	named := false
+
+	for _, name := range names {
+		if name != "" {
+			named = true
+			break
+		}
+	}
+
+	for i, name := range names {
+
+		fields[i] = -1
+
+		if !named {
+			if i < structType.NumField() {
+				fields[i] = i
+			}
+
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+
+		for j := 0; j < structType.NumField(); j++ {
+			field := structType.Field(j)
+
+			tag, _ := fieldTag(field)
+
+			if tag == "-" {
+				continue
+			}
+
+			if tag == name || (tag == "" && field.Name == name) {
+				fields[i] = j
+				break
+			}
+		}
+	}
+
+	return fields
+}
+
+// nullValueField get the value field index of nullable struct like sql.NullString,
+// which has exactly a "Valid bool" field and one value field
+func nullValueField(structType reflect.Type) (int, bool) {
+
+	if structType.NumField() != 2 {
+		return 0, false
+	}
+
+	valid, ok := structType.FieldByName("Valid")
+
+	if !ok || valid.Type.Kind() != reflect.Bool || len(valid.Index) != 1 {
+		return 0, false
+	}
+
+	index := 1 - valid.Index[0]
+
+	if structType.Field(index).PkgPath != "" {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// fieldPath resolve dotted column name like "Address.City" into the index path
+// of nested struct fields, each part is matched by tag, field name, then the case
+// insensitive matching if enabled. pointer to struct fields are walked through
+func (reader *RowReader) fieldPath(structType reflect.Type, name string) ([]int, reflect.StructField, bool) {
+
+	var index []int
+
+	var field reflect.StructField
+
+	for _, part := range strings.Split(name, ".") {
+
+		for structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+
+		if structType.Kind() != reflect.Struct {
+			return nil, field, false
+		}
+
+		var ok bool
+
+		if field, ok = reader.structField(structType, part); !ok {
+			return nil, field, false
+		}
+
+		index = append(index, field.Index...)
+
+		structType = field.Type
+	}
+
+	return index, field, true
+}
+
+// structField find the field of struct type by name, the xlsx tag wins, then
+// the field name, then the case insensitive matching if enabled
+func (reader *RowReader) structField(structType reflect.Type, name string) (reflect.StructField, bool) {
+
+	for _, field := range columnFields(structType) {
+		if tag, _ := fieldTag(field); tag != "-" && tag == name {
+			return field, true
+		}
+	}
+
+	if field, ok := structType.FieldByName(name); ok {
+		if tag, _ := fieldTag(field); tag != "-" {
+			return field, true
+		}
+
+		return field, false
+	}
+
+	if reader.caseInsensitive {
+		if fieldName, ok := foldedFields(reader.foldedFields, structType)[foldName(name)]; ok {
+			return structType.FieldByName(fieldName)
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// indirectType get the element type of pointer type
+func indirectType(t reflect.Type) reflect.Type {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// nilPath check if the index path walks through a nil pointer to struct
+func nilPath(v reflect.Value, index []int) bool {
+
+	for i, n := range index {
+
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return true
+				}
+
+				v = v.Elem()
+			}
+		}
+
+		v = v.Field(n)
+	}
+
+	return false
+}
+
+// unexportedEmbedded get the embedded pointer to unexported struct on the index path
+// before the field, which can't be allocated by reflect
+func unexportedEmbedded(structType reflect.Type, index []int) (reflect.StructField, bool) {
+
+	for i := 0; i+1 < len(index); i++ {
+
+		for structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+
+		field := structType.Field(index[i])
+
+		if field.Anonymous && !field.IsExported() && field.Type.Kind() == reflect.Ptr {
+			return field, true
+		}
+
+		structType = field.Type
+	}
+
+	return reflect.StructField{}, false
+}
+
+// fieldByIndex get the nested field of v by index path like reflect.Value.FieldByIndex,
+// the nil pointers to struct are allocated as walking through
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+
+	for i, n := range index {
+
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+
+				v = v.Elem()
+			}
+		}
+
+		v = v.Field(n)
+	}
+
+	return v
+}
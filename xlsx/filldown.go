@@ -0,0 +1,84 @@
+package xlsx
+
+import (
+	"strings"
+
+	x "github.com/tealeg/xlsx"
+)
+
+// fillDown carry the last non-empty cell value forward into blank cells of
+// the FillDown columns, the sheet rows are not modified
+type fillDown struct {
+	columns []bool    // fill down columns by index
+	last    []*x.Cell // last non-empty cells by index
+}
+
+// newFillDown create fill down state for header, return nil if fill down is disabled
+func (reader *Reader) newFillDown(header *x.Row) *fillDown {
+
+	if !reader.FillDownAll && len(reader.FillDown) == 0 {
+		return nil
+	}
+
+	names := make(map[string]bool)
+
+	for _, name := range reader.FillDown {
+		names[name] = true
+	}
+
+	filler := &fillDown{
+		columns: make([]bool, len(header.Cells)),
+		last:    make([]*x.Cell, len(header.Cells)),
+	}
+
+	for i, cell := range header.Cells {
+		filler.columns[i] = reader.FillDownAll || names[cell.Value]
+	}
+
+	return filler
+}
+
+// fill get the filled copy of row, the row itself is returned if nothing to fill
+func (filler *fillDown) fill(row *x.Row) *x.Row {
+
+	if filler == nil {
+		return row
+	}
+
+	var filled *x.Row
+
+	for i, fill := range filler.columns {
+
+		if !fill {
+			continue
+		}
+
+		if i < len(row.Cells) && strings.TrimSpace(row.Cells[i].Value) != "" {
+			filler.last[i] = row.Cells[i]
+			continue
+		}
+
+		if filler.last[i] == nil {
+			continue
+		}
+
+		if filled == nil {
+			filled = &x.Row{Sheet: row.Sheet, Cells: make([]*x.Cell, len(row.Cells))}
+			copy(filled.Cells, row.Cells)
+		}
+
+		for len(filled.Cells) <= i {
+			filled.Cells = append(filled.Cells, &x.Cell{Row: filled})
+		}
+
+		cell := *filler.last[i]
+		cell.Row = filled
+		filled.Cells[i] = &cell
+	}
+
+	if filled == nil {
+		return row
+	}
+
+	return filled
+}
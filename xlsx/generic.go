@@ -0,0 +1,227 @@
+package xlsx
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/gsdocker/gserrors"
+)
+
+// ErrInvalidRowType the type parameter of generic read functions isn't struct or pointer to struct
+type ErrInvalidRowType struct {
+	Type reflect.Type
+}
+
+func (e *ErrInvalidRowType) Error() string {
+	return "xlsx: invalid row type " + e.Type.String() + ", expect struct or pointer to struct"
+}
+
+// RowError the error of one data row
+type RowError struct {
+	Sheet string // sheet name
+	Row   int    // zero based data row index, same as RowReader.ID
+	Err   error  // underlying error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("xlsx: row(%s:%d): %s", e.Sheet, e.Row, e.Err)
+}
+
+// Unwrap get the underlying error
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// checkRowType check T is struct or pointer to struct
+func checkRowType[T any]() error {
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	if t.Kind() == reflect.Struct || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct) {
+		return nil
+	}
+
+	return &ErrInvalidRowType{t}
+}
+
+// ReadAll read every data row of sheet into a new T, T must be struct or pointer to struct.
+// the sheet errors like ErrSheetNotFound are returned as is, the errors of all rows are
+// aggregated into MultiError of RowError, the good rows are returned with the error if
+// ContinueOnError is enabled
+func ReadAll[T any](r *Reader, sheet string) ([]T, error) {
+
+	if err := checkRowType[T](); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.ReadSheet(sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]T, len(rows))
+
+	errs := make([]error, len(rows))
+
+	for i, row := range rows {
+		errs[i] = row.Read(&vals[i])
+
+		r.progress(i+1, len(rows))
+	}
+
+	return collectRows(r, rows, vals, errs)
+}
+
+// ReadAllMust is like ReadAll but panics on any error, e.g. the sheet not found,
+// it is intended for tests and one-off tools, not for production code
+func ReadAllMust[T any](r *Reader, sheet string) []T {
+
+	vals, err := ReadAll[T](r, sheet)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return vals
+}
+
+// collectRows aggregate the row errors, the rows with error are dropped if ContinueOnError
+// is enabled, otherwise no row is returned on error
+func collectRows[T any](r *Reader, rows []*RowReader, vals []T, errs []error) ([]T, error) {
+
+	var collected []error
+
+	good := vals[:0]
+
+	for i, err := range errs {
+		if err != nil {
+			collected = append(collected, &RowError{Sheet: rows[i].Sheet, Row: rows[i].ID(), Err: err})
+		} else {
+			good = append(good, vals[i])
+		}
+	}
+
+	if len(collected) == 0 {
+		return vals, nil
+	}
+
+	if r.ContinueOnError {
+		return good, &MultiError{collected}
+	}
+
+	return nil, &MultiError{collected}
+}
+
+// ReadKeyed read every data row of sheet like ReadAll, the rows are keyed by the raw
+// cell value of keyColumn. the duplicate keys are reported as errors with row errors
+func ReadKeyed[T any](r *Reader, sheet, keyColumn string) (map[string]T, error) {
+
+	if err := checkRowType[T](); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.ReadSheet(sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(map[string]T, len(rows))
+
+	var errs []error
+
+	for i, row := range rows {
+
+		key, ok := row.Cell(keyColumn)
+
+		if !ok {
+			return nil, gserrors.Newf(nil, "key column %s of sheet %s not found", keyColumn, sheet)
+		}
+
+		var val T
+
+		if _, ok := vals[key]; ok {
+			errs = append(errs, &RowError{Sheet: sheet, Row: row.ID(), Err: gserrors.Newf(nil, "duplicate key '%s' of column %s", key, keyColumn)})
+		} else if err := row.Read(&val); err != nil {
+			errs = append(errs, &RowError{Sheet: sheet, Row: row.ID(), Err: err})
+		} else {
+			vals[key] = val
+		}
+
+		r.progress(i+1, len(rows))
+	}
+
+	if len(errs) == 0 {
+		return vals, nil
+	}
+
+	if r.ContinueOnError {
+		return vals, &MultiError{errs}
+	}
+
+	return nil, &MultiError{errs}
+}
+
+// ReadParallel read every data row of sheet like ReadAll with workers goroutines, the
+// results keep the row order and the errors are aggregated in row order. workers <= 0
+// means runtime.GOMAXPROCS(0). the Reader options, patterns and unmarshalers are shared
+// by all workers and must not be modified while reading
+func ReadParallel[T any](r *Reader, sheet string, workers int) ([]T, error) {
+
+	if err := checkRowType[T](); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.ReadSheet(sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	vals := make([]T, len(rows))
+
+	errs := make([]error, len(rows))
+
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+
+	// the progress of workers is counted and reported under the lock, so Progress is never called concurrently
+	var mutex sync.Mutex
+
+	done := 0
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range next {
+				errs[i] = rows[i].Read(&vals[i])
+
+				mutex.Lock()
+				done++
+				r.progress(done, len(rows))
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for i := range rows {
+		next <- i
+	}
+
+	close(next)
+
+	wg.Wait()
+
+	return collectRows(r, rows, vals, errs)
+}
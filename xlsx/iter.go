@@ -0,0 +1,43 @@
+//go:build go1.23
+
+package xlsx
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// errStopRows stop reading rows when the range loop breaks
+var errStopRows = errors.New("xlsx: stop rows")
+
+// Rows get the iterator of sheet rows, the rows are produced lazily like Stream.
+// the error, e.g. ErrSheetNotFound, is yielded with nil row and ends the iteration
+//
+//	for row, err := range reader.Rows("Sheet1") {
+//		...
+//	}
+func (reader *Reader) Rows(sheetName string) iter.Seq2[*RowReader, error] {
+
+	return func(yield func(*RowReader, error) bool) {
+
+		sheet, err := reader.lookupSheet(sheetName)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		err = reader.eachRow(context.Background(), sheet, 0, 0, func(row *RowReader) error {
+			if !yield(row, nil) {
+				return errStopRows
+			}
+
+			return nil
+		})
+
+		if err != nil && err != errStopRows {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//go:build go1.23
+
+package xlsx
+
+import "testing"
+
+func TestRows(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+		[]string{"3"},
+	)
+
+	var counts []int
+
+	for row, err := range reader.Rows("Sheet1") {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var val countRow
+
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		counts = append(counts, val.Count)
+
+		if val.Count == 2 {
+			break
+		}
+	}
+
+	if len(counts) != 2 || counts[1] != 2 {
+		t.Fatalf("expect break at second row, got %v", counts)
+	}
+
+	errs := 0
+
+	for row, err := range reader.Rows("Sheet2") {
+		if _, ok := err.(*ErrSheetNotFound); !ok || row != nil {
+			t.Fatalf("expect ErrSheetNotFound, got %v", err)
+		}
+
+		errs++
+	}
+
+	reader.SkipRows = -1
+
+	for _, err := range reader.Rows("Sheet1") {
+		if err == nil {
+			t.Fatal("expect negative skip rows error")
+		}
+
+		errs++
+	}
+
+	if errs != 2 {
+		t.Fatalf("expect 2 errors, got %d", errs)
+	}
+}
+
+func TestRowsClose(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+		[]string{"3"},
+	)
+
+	var errs []error
+
+	for row, err := range reader.Rows("Sheet1") {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if row.ID() == 0 {
+			reader.Close()
+		}
+	}
+
+	if len(errs) != 1 || errs[0] != ErrClosed {
+		t.Fatalf("expect ErrClosed after close in loop, got %v", errs)
+	}
+}
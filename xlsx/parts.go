@@ -0,0 +1,339 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gsdocker/gserrors"
+	x "github.com/tealeg/xlsx"
+)
+
+// parts the cell annotations which are dropped by tealeg/xlsx while loading, e.g. the
+// hyperlinks and comments of worksheets. they are parsed from the workbook zip parts on first use
+type parts struct {
+	once       sync.Once                    // parse once
+	content    func() ([]byte, error)       // get the workbook file content
+	hyperlinks map[string]map[string]string // hyperlink targets by sheet name and A1 ref
+	comments   map[string]map[string]string // comment text by sheet name and A1 ref
+	err        error                        // parse error
+}
+
+// newParts create the lazy parsed parts of workbook content
+func newParts(content func() ([]byte, error)) *parts {
+	return &parts{content: content}
+}
+
+// hyperlink get the hyperlink target of sheet cell, return false if the cell has no hyperlink
+func (p *parts) hyperlink(sheet, ref string) (string, bool, error) {
+
+	if err := p.load(); err != nil {
+		return "", false, err
+	}
+
+	target, ok := p.hyperlinks[sheet][ref]
+
+	return target, ok, nil
+}
+
+// comment get the comment text of sheet cell, return empty string if the cell has no comment
+func (p *parts) comment(sheet, ref string) (string, error) {
+
+	if err := p.load(); err != nil {
+		return "", err
+	}
+
+	return p.comments[sheet][ref], nil
+}
+
+func (p *parts) load() error {
+
+	p.once.Do(func() {
+		p.err = p.parse()
+	})
+
+	return p.err
+}
+
+// xmlSheet the sheet entry of xl/workbook.xml
+type xmlSheet struct {
+	Name string `xml:"name,attr"`
+	ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+}
+
+// xmlRelationship the relationship of .rels part
+type xmlRelationship struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+// xmlHyperlink the hyperlink of worksheet, the external target is the relationship of
+// r:id, the location is the place in the workbook, e.g. "Sheet2!A1"
+type xmlHyperlink struct {
+	Ref      string `xml:"ref,attr"`
+	ID       string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	Location string `xml:"location,attr"`
+}
+
+// xmlComment the comment of comments part, the text is plain or rich text runs
+type xmlComment struct {
+	Ref  string   `xml:"ref,attr"`
+	Text string   `xml:"text>t"`
+	Runs []string `xml:"text>r>t"`
+}
+
+// commentsType the relationship type of worksheet comments part
+const commentsType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments"
+
+func (p *parts) parse() error {
+
+	content, err := p.content()
+
+	if err != nil {
+		return gserrors.Newf(err, "read xlsx content error")
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+
+	if err != nil {
+		return gserrors.Newf(err, "open xlsx content error")
+	}
+
+	files := make(map[string]*zip.File)
+
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	var workbook struct {
+		Sheets []xmlSheet `xml:"sheets>sheet"`
+	}
+
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return err
+	}
+
+	rels, err := readRels(files, "xl/workbook.xml")
+
+	if err != nil {
+		return err
+	}
+
+	p.hyperlinks = make(map[string]map[string]string)
+
+	p.comments = make(map[string]map[string]string)
+
+	for _, sheet := range workbook.Sheets {
+
+		rel, ok := rels[sheet.ID]
+
+		if !ok {
+			continue
+		}
+
+		part := partPath("xl/workbook.xml", rel.Target)
+
+		sheetRels, err := readRels(files, part)
+
+		if err != nil {
+			return err
+		}
+
+		hyperlinks, err := readHyperlinks(files, part, sheetRels)
+
+		if err != nil {
+			return err
+		}
+
+		p.hyperlinks[sheet.Name] = hyperlinks
+
+		for _, rel := range sheetRels {
+			if rel.Type != commentsType {
+				continue
+			}
+
+			comments, err := readComments(files, partPath(part, rel.Target))
+
+			if err != nil {
+				return err
+			}
+
+			p.comments[sheet.Name] = comments
+		}
+	}
+
+	return nil
+}
+
+// readHyperlinks read the hyperlinks of worksheet part keyed by A1 ref, the range refs
+// like "A1:B2" are expanded to the cells
+func readHyperlinks(files map[string]*zip.File, part string, rels map[string]xmlRelationship) (map[string]string, error) {
+
+	file, ok := files[part]
+
+	if !ok {
+		return nil, nil
+	}
+
+	r, err := file.Open()
+
+	if err != nil {
+		return nil, gserrors.Newf(err, "open xlsx part %s error", part)
+	}
+
+	defer r.Close()
+
+	hyperlinks := make(map[string]string)
+
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			return hyperlinks, nil
+		}
+
+		if err != nil {
+			return nil, gserrors.Newf(err, "parse xlsx part %s error", part)
+		}
+
+		start, ok := token.(xml.StartElement)
+
+		if !ok || start.Name.Local != "hyperlink" {
+			continue
+		}
+
+		var hyperlink xmlHyperlink
+
+		if err := decoder.DecodeElement(&hyperlink, &start); err != nil {
+			return nil, gserrors.Newf(err, "parse hyperlink of xlsx part %s error", part)
+		}
+
+		target := rels[hyperlink.ID].Target
+
+		if hyperlink.Location != "" && target != "" {
+			target += "#" + hyperlink.Location
+		} else if hyperlink.Location != "" {
+			target = hyperlink.Location
+		}
+
+		for _, ref := range expandRef(hyperlink.Ref) {
+			hyperlinks[ref] = target
+		}
+	}
+}
+
+// readComments read the comment text of comments part keyed by A1 ref
+func readComments(files map[string]*zip.File, part string) (map[string]string, error) {
+
+	var comments struct {
+		Comments []xmlComment `xml:"commentList>comment"`
+	}
+
+	if err := decodePart(files, part, &comments); err != nil {
+		return nil, err
+	}
+
+	texts := make(map[string]string, len(comments.Comments))
+
+	for _, comment := range comments.Comments {
+		texts[comment.Ref] = comment.Text + strings.Join(comment.Runs, "")
+	}
+
+	return texts, nil
+}
+
+// readRels read the relationships of part keyed by id, return nil if the part has no .rels
+func readRels(files map[string]*zip.File, part string) (map[string]xmlRelationship, error) {
+
+	var rels struct {
+		Relationships []xmlRelationship `xml:"Relationship"`
+	}
+
+	relsPart := path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+
+	if _, ok := files[relsPart]; !ok {
+		return nil, nil
+	}
+
+	if err := decodePart(files, relsPart, &rels); err != nil {
+		return nil, err
+	}
+
+	relationships := make(map[string]xmlRelationship, len(rels.Relationships))
+
+	for _, rel := range rels.Relationships {
+		relationships[rel.ID] = rel
+	}
+
+	return relationships, nil
+}
+
+// decodePart decode the xml part of zip into v
+func decodePart(files map[string]*zip.File, part string, v interface{}) error {
+
+	file, ok := files[part]
+
+	if !ok {
+		return gserrors.Newf(nil, "xlsx part %s not found", part)
+	}
+
+	r, err := file.Open()
+
+	if err != nil {
+		return gserrors.Newf(err, "open xlsx part %s error", part)
+	}
+
+	defer r.Close()
+
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return gserrors.Newf(err, "parse xlsx part %s error", part)
+	}
+
+	return nil
+}
+
+// partPath resolve the relationship target relative to the source part, the absolute
+// target begins with "/"
+func partPath(source, target string) string {
+
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+
+	return path.Join(path.Dir(source), target)
+}
+
+// expandRef expand the A1 range ref like "A1:B2" to the cell refs, the single cell ref
+// is returned as is
+func expandRef(ref string) []string {
+
+	from, to, ok := strings.Cut(ref, ":")
+
+	if !ok {
+		return []string{ref}
+	}
+
+	x1, y1, err1 := x.GetCoordsFromCellIDString(from)
+	x2, y2, err2 := x.GetCoordsFromCellIDString(to)
+
+	if err1 != nil || err2 != nil {
+		return []string{from}
+	}
+
+	var refs []string
+
+	for row := y1; row <= y2; row++ {
+		for col := x1; col <= x2; col++ {
+			refs = append(refs, x.GetCellIDStringFromCoords(col, row))
+		}
+	}
+
+	return refs
+}
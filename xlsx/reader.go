@@ -1,11 +1,25 @@
 package xlsx
 
 import (
+	"context"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gsdocker/gserrors"
 	"github.com/gsdocker/gslogger"
@@ -15,6 +29,24 @@ import (
 // UnmarshalF .
 type UnmarshalF func(reflect.Value, string) error
 
+var timeType = reflect.TypeOf(time.Time{})
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+var ipType = reflect.TypeOf(net.IP{})
+
+var urlType = reflect.TypeOf(url.URL{})
+
+var bigFloatType = reflect.TypeOf(big.Float{})
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
 // ErrUnmarshalField .
 type ErrUnmarshalField struct {
 	Key   string
@@ -23,7 +55,7 @@ type ErrUnmarshalField struct {
 }
 
 func (e *ErrUnmarshalField) Error() string {
-	return "json: cannot unmarshal object key " + strconv.Quote(e.Key) + " into unexported field " + e.Field.Name + " of type " + e.Type.String()
+	return "xlsx: cannot unmarshal column " + strconv.Quote(e.Key) + " into unexported field " + e.Field.Name + " of type " + e.Type.String()
 }
 
 // ErrInvalidUnmarshal .
@@ -42,32 +74,245 @@ func (e *ErrInvalidUnmarshal) Error() string {
 	return "xlsx: Unmarshal(nil " + e.Type.String() + ")"
 }
 
+// ErrClosed is returned by reading the reader after Close
+var ErrClosed = errors.New("xlsx: reader is closed")
+
+// ErrSheetNotFound .
+type ErrSheetNotFound struct {
+	Sheet string
+}
+
+func (e *ErrSheetNotFound) Error() string {
+	return "xlsx: sheet " + strconv.Quote(e.Sheet) + " not found"
+}
+
+// ErrUnknownColumns header columns which can't be mapped to struct field
+type ErrUnknownColumns struct {
+	Sheet   string
+	Columns []string
+}
+
+func (e *ErrUnknownColumns) Error() string {
+	return "xlsx: unknown columns of sheet " + strconv.Quote(e.Sheet) + ": " + strings.Join(e.Columns, ", ")
+}
+
+// ErrMissingFields struct fields which are not mapped by any header column
+type ErrMissingFields struct {
+	Sheet  string
+	Fields []string
+}
+
+func (e *ErrMissingFields) Error() string {
+	return "xlsx: missing columns of fields in sheet " + strconv.Quote(e.Sheet) + ": " + strings.Join(e.Fields, ", ")
+}
+
+// ErrCellError the excel error cell like "#DIV/0!" read with ErrorCellAsError
+type ErrCellError struct {
+	Sheet string // sheet name
+	A1    string // excel A1 reference of cell
+	Code  string // excel error code, e.g. "#N/A"
+}
+
+func (e *ErrCellError) Error() string {
+	return "xlsx: cell[" + e.Sheet + "!" + e.A1 + "] is excel error " + e.Code
+}
+
+// ErrorCellPolicy the handling of excel error cells like "#N/A" and "#DIV/0!"
+type ErrorCellPolicy int
+
+// the error cell policies, the column Unmarshalers get the error code as cell value
+const (
+	ErrorCellAsError ErrorCellPolicy = iota // return ErrCellError
+	ErrorCellAsEmpty                        // read as empty cell, the default tag applies
+	ErrorCellSkip                           // leave the field untouched
+)
+
+// ErrDuplicateColumns header columns which are mapped to the same field of previous column
+type ErrDuplicateColumns struct {
+	Sheet   string
+	Columns []string
+}
+
+func (e *ErrDuplicateColumns) Error() string {
+	return "xlsx: duplicate columns of sheet " + strconv.Quote(e.Sheet) + ": " + strings.Join(e.Columns, ", ")
+}
+
+// MultiError collect all cell errors of one row
+type MultiError struct {
+	errors []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.errors))
+
+	for i, err := range e.errors {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Errors get all collected errors
+func (e *MultiError) Errors() []error {
+	return e.errors
+}
+
+// RowErrors get the collected errors of rows, see ReadAll
+func (e *MultiError) RowErrors() (errs []*RowError) {
+
+	for _, err := range e.errors {
+		if rowErr, ok := err.(*RowError); ok {
+			errs = append(errs, rowErr)
+		}
+	}
+
+	return
+}
+
+// FieldErrors get the errors of cells aggregated in e, see FieldErrors
+func (e *MultiError) FieldErrors() []*CellError {
+	return FieldErrors(e)
+}
+
+// CellError the conversion or validation error of one cell
+type CellError struct {
+	Sheet  string // sheet name
+	Row    int    // zero based data row index, same as RowReader.ID
+	Column string // resolved column name
+	A1     string // excel A1 reference of cell, e.g. "B3"
+	Err    error  // underlying error
+}
+
+func (e *CellError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap get the underlying error
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors collect the CellError of err, which can be the error of RowReader.Read, ReadAll
+// or Reader.Validate, the MultiError and RowError are walked through
+func FieldErrors(err error) (errs []*CellError) {
+
+	switch e := err.(type) {
+	case *CellError:
+		errs = append(errs, e)
+	case *RowError:
+		errs = append(errs, FieldErrors(e.Err)...)
+	case *MultiError:
+		for _, err := range e.errors {
+			errs = append(errs, FieldErrors(err)...)
+		}
+	}
+
+	return
+}
+
 // RowReader row reader
 type RowReader struct {
-	gslogger.Log                           // mixin logger
-	Sheet        string                    // sheet name
-	nameMapping  map[string]string         // name mapping
-	unmarshalers map[string]UnmarshalF     // unmarshal functions
-	pattern      map[string]*regexp.Regexp // column pattern
-	Split        string                    // split chars
-	header       *x.Row                    // current row
-	row          *x.Row                    // current row
-	id           int                       // row id
-}
-
-func (reader *Reader) newRowReader(name string, header, row *x.Row, id int) *RowReader {
+	gslogger.Log                                       // mixin logger
+	Sheet                  string                      // sheet name
+	nameMapping            map[string]string           // name mapping
+	unmarshalers           map[string]UnmarshalF       // unmarshal functions
+	types                  map[reflect.Type]UnmarshalF // unmarshal functions by type
+	pattern                map[string]*regexp.Regexp   // column pattern
+	patternStr             map[string]string           // column pattern string
+	patterns               *sync.Map                   // compiled pattern string cache
+	splits                 map[string]string           // split chars by column
+	emptyAsZero            bool                        // empty cell as zero value
+	trimSpace              bool                        // trim non string values
+	trimStrings            bool                        // trim string values
+	trueValues             []string                    // extra true values of bool cell
+	falseValues            []string                    // extra false values of bool cell
+	nullValues             []string                    // sentinel values read as empty cell
+	errorCells             ErrorCellPolicy             // handling of excel error cells
+	formattedNumbers       bool                        // parse grouped and percent numbers
+	decimalSeparator       rune                        // decimal separator of formatted numbers
+	thousandsSeparator     rune                        // thousands separator of formatted numbers
+	durationNanoseconds    bool                        // bare number duration as nanoseconds
+	preferCachedValue      bool                        // read formula cells as cached result
+	percentAsFraction      bool                        // read percent numbers as fraction
+	rejectNonFinite        bool                        // error on NaN and Inf floats
+	keySplit               string                      // key value split chars of map cells
+	base64                 *base64.Encoding            // encoding of []byte cells
+	parts                  *parts                      // workbook parts dropped by tealeg, nil for the in memory file
+	Split                  string                      // split chars
+	splitRegexp            *regexp.Regexp              // split regexp replacing Split
+	timeLayout             string                      // default time layout
+	date1904               bool                        // workbook date system
+	header                 *x.Row                      // current row
+	row                    *x.Row                      // current row
+	id                     int                         // row id
+	rownum                 int                         // zero based sheet row index
+	collectErrors          bool                        // collect all cell errors
+	disallowUnknownColumns bool                        // error on unknown columns
+	requireAllFields       bool                        // error on fields without column
+	disallowDuplicates     bool                        // error on duplicate columns
+	caseInsensitive        bool                        // case insensitive column matching
+	headerless             bool                        // map columns by "col:N" tag
+	nameFunc               func(string) string         // header name normalization
+	foldedFields           *sync.Map                   // folded field name index cache
+	mappings               *sync.Map                   // column mapping cache of the sheet, map[reflect.Type]*fieldMapping
+}
+
+func (reader *Reader) newRowReader(name string, header, row *x.Row, id, rownum int, mappings *sync.Map) *RowReader {
 	return &RowReader{
-		nameMapping:  reader.NameMapping,
-		unmarshalers: reader.Unmarshalers,
-		pattern:      reader.Pattern,
-		Log:          reader.Log,
-		Sheet:        name,
-		header:       header,
-		row:          row,
-		Split:        ",",
+		nameMapping:            reader.NameMapping,
+		unmarshalers:           reader.Unmarshalers,
+		types:                  reader.types,
+		pattern:                reader.Pattern,
+		patternStr:             reader.PatternStr,
+		patterns:               &reader.patterns,
+		splits:                 reader.Splits,
+		emptyAsZero:            reader.EmptyAsZero,
+		trimSpace:              reader.TrimSpace,
+		trimStrings:            reader.TrimStrings,
+		trueValues:             reader.TrueValues,
+		falseValues:            reader.FalseValues,
+		nullValues:             reader.NullValues,
+		errorCells:             reader.ErrorCells,
+		formattedNumbers:       reader.FormattedNumbers,
+		decimalSeparator:       reader.DecimalSeparator,
+		thousandsSeparator:     reader.ThousandsSeparator,
+		durationNanoseconds:    reader.DurationNanoseconds,
+		preferCachedValue:      reader.PreferCachedValue,
+		percentAsFraction:      reader.PercentAsFraction,
+		rejectNonFinite:        reader.RejectNonFinite,
+		keySplit:               reader.KeySplit,
+		base64:                 reader.Base64,
+		parts:                  reader.parts,
+		Log:                    reader.Log,
+		Sheet:                  name,
+		header:                 header,
+		row:                    row,
+		id:                     id,
+		rownum:                 rownum,
+		Split:                  ",",
+		splitRegexp:            reader.SplitRegexp,
+		timeLayout:             reader.TimeLayout,
+		date1904:               reader.date1904,
+		collectErrors:          reader.CollectErrors,
+		disallowUnknownColumns: reader.DisallowUnknownColumns,
+		requireAllFields:       reader.RequireAllFields,
+		disallowDuplicates:     reader.DisallowDuplicateColumns,
+		caseInsensitive:        reader.CaseInsensitive,
+		headerless:             reader.Headerless,
+		nameFunc:               reader.NameFunc,
+		foldedFields:           &reader.foldedFields,
+		mappings:               mappings,
 	}
 }
 
+// ID get the zero based data row index
+func (reader *RowReader) ID() int {
+	return reader.id
+}
+
+// Read unmarshal row into val like Unmarshal, the panics of unmarshal functions are
+// recovered and returned as error. the row can be read into different struct types,
+// concurrently too, the column mapping is cached per sheet read and struct type
 func (reader *RowReader) Read(val interface{}) (err error) {
 
 	defer func() {
@@ -76,15 +321,26 @@ func (reader *RowReader) Read(val interface{}) (err error) {
 		}
 	}()
 
+	return reader.Unmarshal(val)
+}
+
+// Unmarshal unmarshal row into val, val must be a pointer to struct or a pointer to
+// struct pointer. unlike Read, the typed errors are returned untouched and panics are
+// not recovered
+func (reader *RowReader) Unmarshal(val interface{}) error {
+
 	rv := reflect.ValueOf(val)
 
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return &ErrInvalidUnmarshal{reflect.TypeOf(val)}
 	}
 
-	if rv.Elem().IsNil() {
+	if rv.Elem().Kind() == reflect.Ptr {
 		rv = rv.Elem()
-		rv.Set(reflect.New(rv.Type().Elem()))
+
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
 	}
 
 	if rv.Elem().Kind() != reflect.Struct {
@@ -93,186 +349,2016 @@ func (reader *RowReader) Read(val interface{}) (err error) {
 
 	rv = reflect.Indirect(rv)
 
-	for i, cell := range reader.row.Cells {
-		colname := reader.header.Cells[i].Value
-		key := fmt.Sprintf("%s.%s", reader.Sheet, colname)
+	mapping := reader.mapping(rv.Type())
 
-		if name, ok := reader.nameMapping[key]; ok {
-			colname = name
-			key = fmt.Sprintf("%s.%s", reader.Sheet, name)
+	for _, index := range mapping.groups {
+		field := fieldByIndex(rv, index)
+		field.Set(reflect.Zero(field.Type()))
+	}
+
+	for _, index := range mapping.sheets {
+		fieldByIndex(rv, index).SetString(reader.Sheet)
+	}
+
+	for _, index := range mapping.rownums {
+		if field := fieldByIndex(rv, index); field.CanInt() {
+			field.SetInt(int64(reader.rownum + 1))
+		} else {
+			field.SetUint(uint64(reader.rownum + 1))
 		}
+	}
 
-		if reader.unmarshalers != nil {
-			if f, ok := reader.unmarshalers[key]; ok {
-				if err := f(reflect.Indirect(rv), cell.Value); err != nil {
-					return gserrors.Newf(err, "can't conv cell[%s:%d] '%s'", colname, reader.id, cell.Value)
-				}
-				continue
-			}
+	var errs []error
+
+	if reader.disallowUnknownColumns && len(mapping.unknown) != 0 {
+		err := &ErrUnknownColumns{Sheet: reader.Sheet, Columns: mapping.unknown}
+
+		if !reader.collectErrors {
+			return err
 		}
 
-		field := rv.FieldByName(colname)
+		errs = append(errs, err)
+	}
+
+	if reader.requireAllFields && len(mapping.missing) != 0 {
+		err := &ErrMissingFields{Sheet: reader.Sheet, Fields: mapping.missing}
 
-		if !field.IsValid() {
-			reader.W("can't unmarshal col(%s)", colname)
-			continue
+		if !reader.collectErrors {
+			return err
+		}
+
+		errs = append(errs, err)
+	}
+
+	// the missing required fields are errors without RequireAllFields, which reports them with all missing fields
+	if !reader.requireAllFields && len(mapping.required) != 0 {
+		err := &ErrMissingFields{Sheet: reader.Sheet, Fields: mapping.required}
+
+		if !reader.collectErrors {
+			return err
+		}
+
+		errs = append(errs, err)
+	}
+
+	if reader.disallowDuplicates && len(mapping.duplicates) != 0 {
+		err := &ErrDuplicateColumns{Sheet: reader.Sheet, Columns: mapping.duplicates}
+
+		if !reader.collectErrors {
+			return err
 		}
 
-		if reader.readBuiltinType(key, cell.Value, field) {
+		errs = append(errs, err)
+	}
+
+	if !reader.headerless && len(reader.row.Cells) > len(reader.header.Cells) && !isBlankRow(&x.Row{Cells: reader.row.Cells[len(reader.header.Cells):]}) {
+		reader.W("row(%s:%d) has %d cells more than header", reader.Sheet, reader.id, len(reader.row.Cells)-len(reader.header.Cells))
+	}
+
+	for _, col := range mapping.columns {
+
+		cell := &x.Cell{}
+
+		if col.index < len(reader.row.Cells) {
+			cell = reader.row.Cells[col.index]
+
+			if reader.isNull(cell.Value) {
+				cell = &x.Cell{}
+			}
+		} else if col.opts.Get("default") == "" && !col.opts.Has("required") {
 			continue
 		}
 
+		if err := reader.readCell(rv, col, cell); err != nil {
+
+			if col.err == nil {
+				err = &CellError{Sheet: reader.Sheet, Row: reader.id, Column: col.name, A1: reader.ref(col), Err: err}
+			}
+
+			if !reader.collectErrors {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	// the composite keys are set after other fields
+	for _, key := range mapping.keys {
+		if err := reader.readKey(rv, key); err != nil {
+
+			if !reader.collectErrors {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 0 {
+		return &MultiError{errs}
 	}
 
 	return nil
 }
 
-func (reader *RowReader) readBuiltinType(colname string, val string, assign reflect.Value) bool {
+// readKey set the composite key field to the values of source columns joined by separator
+func (reader *RowReader) readKey(rv reflect.Value, key *keyField) error {
 
-	switch assign.Type().Kind() {
-	case reflect.Bool:
-		if val == "true" || val == "1" {
-			assign.SetBool(true)
-		} else {
-			assign.SetBool(false)
+	vals := make([]string, len(key.columns))
+
+	for i, column := range key.columns {
+
+		val, ok := reader.Cell(column)
+
+		if !ok {
+			return gserrors.Newf(nil, "key column %s of field %s not found in sheet %s", column, key.name, reader.Sheet)
 		}
 
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := strconv.ParseInt(val, 0, 64)
+		vals[i] = strings.TrimSpace(val)
+	}
+
+	fieldByIndex(rv, key.field).SetString(strings.Join(vals, key.sep))
+
+	return nil
+}
+
+// readCell read cell of column into struct value rv
+func (reader *RowReader) readCell(rv reflect.Value, col *column, cell *x.Cell) error {
+
+	if col.err != nil {
+		return col.err
+	}
+
+	// the hyperlink column reads the target of cell, the display value is the fallback
+	if col.opts.Has("hyperlink") {
+		hyperlinked, err := reader.hyperlink(col, cell)
 
 		if err != nil {
-			gserrors.Panicf(err, "can't conv cell[%s:%d] '%s' to int", colname, reader.id, val)
+			return err
 		}
 
-		assign.SetInt(v)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		cell = hyperlinked
+	}
 
-		v, err := strconv.ParseUint(val, 0, 64)
+	// the comment column reads the comment text of cell instead of the value
+	if col.opts.Has("comment") {
+		commented, err := reader.comment(col)
 
 		if err != nil {
-			gserrors.Panicf(err, "can't conv cell[%s:%d] '%s' to uint", colname, reader.id, val)
+			return err
 		}
 
-		assign.SetUint(v)
+		cell = commented
+	}
 
-	case reflect.Float32, reflect.Float64:
+	if col.unmarshaler != nil {
+		if err := col.unmarshaler(rv, cell.Value); err != nil {
+			return gserrors.Newf(err, "can't conv cell[%s] '%s'", reader.cell(col), cell.Value)
+		}
+
+		return nil
+	}
 
-		val, err := strconv.ParseFloat(val, 64)
+	if col.field == nil && col.group == nil {
+		if !reader.disallowUnknownColumns {
+			reader.W("can't unmarshal col(%s)", col.name)
+		}
 
-		if err != nil {
-			gserrors.Panicf(err, "can't conv cell[%s:%d] '%s' to float", colname, reader.id, val)
+		return nil
+	}
+
+	// the formula text is read instead of the error result without PreferCachedValue
+	if cell.Type() == x.CellTypeError && (reader.preferCachedValue || cell.Formula() == "") {
+		switch reader.errorCells {
+		case ErrorCellSkip:
+			return nil
+		case ErrorCellAsEmpty:
+			cell = &x.Cell{}
+		default:
+			return &ErrCellError{Sheet: reader.Sheet, A1: reader.ref(col), Code: cell.Value}
 		}
+	}
 
-		assign.SetFloat(val)
+	if col.opts.Has("required") && col.opts.Get("default") == "" && strings.TrimSpace(cell.Value) == "" {
+		return gserrors.Newf(nil, "cell[%s] is required", reader.cell(col))
+	}
 
-	case reflect.String:
-		assign.SetString(val)
-	case reflect.Array:
-	case reflect.Slice:
+	// the default replaces the empty cell before the type specific readers, e.g. time.Time
+	if def := col.opts.Get("default"); def != "" && strings.TrimSpace(cell.Value) == "" && (reader.preferCachedValue || cell.Formula() == "") {
+		cell = &x.Cell{Value: def}
+	}
 
-		pattern, ok := reader.pattern[colname]
+	var field reflect.Value
 
-		if !ok {
-			gserrors.Panicf(nil, "can't conv %s(%d), not found convert pattern", colname, reader.id)
+	if col.group != nil {
+		// the empty cells of group don't create the element
+		if strings.TrimSpace(cell.Value) == "" {
+			return nil
 		}
 
-		subs := strings.Split(val, reader.Split)
+		field = col.group.elemField(rv)
+	} else {
+		// the nil pointers to nested struct are allocated by the first non-empty cell
+		if strings.TrimSpace(cell.Value) == "" && col.opts.Get("default") == "" && nilPath(rv, col.field) {
+			return nil
+		}
 
-		slice := reflect.MakeSlice(assign.Type(), 0, len(subs))
+		field = fieldByIndex(rv, col.field)
+	}
 
-		subType := assign.Type().Elem()
+	if _, ok := reader.types[field.Type()]; !ok && field.Type() == timeType {
+		return reader.readTime(col, cell, field)
+	}
 
-		if subType.Kind() == reflect.Ptr {
-			subType = subType.Elem()
+	// the pointer to time is left nil by the empty cell
+	if _, ok := reader.types[field.Type()]; !ok && field.Type() == reflect.PointerTo(timeType) {
+		if strings.TrimSpace(cell.Value) == "" {
+			return nil
 		}
 
-		for _, sub := range subs {
-			matched := pattern.FindStringSubmatch(sub)
+		if field.IsNil() {
+			field.Set(reflect.New(timeType))
+		}
 
-			if matched == nil {
+		return reader.readTime(col, cell, field.Elem())
+	}
 
-				if sub != "" {
-					gserrors.Panicf(nil, "can't conv cell[%s:%d] '%s'", colname, reader.id, val)
-				}
+	if _, ok := reader.types[field.Type()]; !ok && field.Kind() == reflect.Interface && field.NumMethod() == 0 {
+		return reader.readInterface(col, cell, field)
+	}
 
-				continue
-			}
+	val := cellValue(cell, field.Type())
 
-			subval := reflect.New(subType)
+	numeric := cell.Type() == x.CellTypeNumeric
 
-			for i, match := range matched[1:] {
+	if !reader.percentAsFraction {
+		if percent, ok := wholePercent(cell, field.Type()); ok {
+			val = percent
+		}
+	}
 
-				if match == "" {
-					continue
-				}
+	if col.opts.Has("text") {
+		val, numeric = textValue(cell), false
+	}
 
-				name := fmt.Sprintf("%s.%s", colname, subType.Field(i).Name)
-				reader.readBuiltinType(name, match, reflect.Indirect(subval).Field(i))
-			}
+	if !reader.preferCachedValue && cell.Formula() != "" {
+		val, numeric = "="+cell.Formula(), false
+	}
+
+	// the stored number of numeric cell is formatted by strconv, not by the separators
+	if numeric {
+		numericCol := *col
+		numericCol.numeric = true
+		col = &numericCol
+	}
+
+	if ok, err := reader.readBuiltinType(col, val, field); !ok {
+		reader.W("can't unmarshal col(%s) of type %s", col.name, field.Type())
+	} else if err != nil {
+		return err
+	}
+
+	return reader.validate(col, field)
+}
+
+// cellValue get the cell value for field type, the typed accessors are used for
+// numeric and bool cells read into numeric and bool fields, so the float fields get the
+// exact stored double whatever the number format displays. other cells fall back to
+// the cell's string value, which is the concatenated plain text of rich text runs
+func cellValue(cell *x.Cell, fieldType reflect.Type) string {
+
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	kind := fieldType.Kind()
 
-			slice = reflect.Append(slice, subval)
+	switch cell.Type() {
+	case x.CellTypeBool:
+		if kind == reflect.Bool {
+			return strconv.FormatBool(cell.Bool())
 		}
 
-		assign.Set(slice)
+	case x.CellTypeNumeric:
 
-	default:
-		return false
+		f, err := cell.Float()
+
+		if err != nil {
+			break
+		}
+
+		switch kind {
+		case reflect.Float32, reflect.Float64:
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+				return strconv.FormatFloat(f, 'f', -1, 64)
+			}
+		case reflect.Bool:
+			return strconv.FormatBool(cell.Bool())
+		}
 	}
 
-	return true
+	return cell.Value
 }
 
-// Reader xlsx reader
-type Reader struct {
-	gslogger.Log                           // mixin log
-	file         *x.File                   // xlsx file
-	Pattern      map[string]*regexp.Regexp // subtype pattern
-	Unmarshalers map[string]UnmarshalF     // unmarshal functions
-	NameMapping  map[string]string         // name mapping
+// readInterface read interface{} field by the cell type: bool cells as bool, integral numeric
+// cells as int64, other numeric cells as float64 and other cells as string, empty cells as nil.
+// the numeric date cells are read as number too
+func (reader *RowReader) readInterface(col *column, cell *x.Cell, assign reflect.Value) error {
+
+	if cell.Value == "" {
+		assign.Set(reflect.Zero(assign.Type()))
+		return nil
+	}
+
+	var val interface{} = reader.trim(cell.Value, reflect.String)
+
+	switch cell.Type() {
+	case x.CellTypeBool:
+		val = cell.Bool()
+	case x.CellTypeNumeric:
+		if f, err := cell.Float(); err != nil {
+			reader.W("can't read numeric cell[%s] '%s' as number", reader.cell(col), cell.Value)
+		} else if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			val = int64(f)
+		} else {
+			val = f
+		}
+	}
+
+	assign.Set(reflect.ValueOf(val))
+
+	return nil
 }
 
-// NewReader create new xlsx file reader
-func NewReader(filename string) (*Reader, error) {
-	file, err := x.OpenFile(filename)
+// wholePercent get the whole percent of numeric cell with percent format read into numeric
+// field, e.g. the stored 0.12 of cell displayed as "12%" is "12"
+func wholePercent(cell *x.Cell, fieldType reflect.Type) (string, bool) {
+
+	if cell.Type() != x.CellTypeNumeric || !strings.Contains(cell.NumFmt, "%") {
+		return "", false
+	}
+
+	switch indirectType(fieldType).Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return "", false
+	}
+
+	f, err := cell.Float()
 
 	if err != nil {
-		return nil, gserrors.Newf(err, "create new xlsx reader error :%s", filename)
+		return "", false
 	}
 
-	return &Reader{
-		Log:  gslogger.Get("xlsx"),
-		file: file,
-	}, err
+	// round to the 15 significant digits of excel, 0.12*100 is 12.000000000000002
+	percent, _ := strconv.ParseFloat(strconv.FormatFloat(f*100, 'g', 15, 64), 64)
+
+	return strconv.FormatFloat(percent, 'f', -1, 64), true
 }
 
-// Read read all rows
-func (reader *Reader) Read(sheetName string) (rows []*RowReader) {
+// hyperlink get the cell of hyperlink target for the "hyperlink" tag option, return the
+// cell as is if it has no hyperlink or the workbook is built in memory
+func (reader *RowReader) hyperlink(col *column, cell *x.Cell) (*x.Cell, error) {
 
-	var sheet *x.Sheet
-	ok := false
+	if reader.parts == nil {
+		return cell, nil
+	}
 
-	for _, sheet = range reader.file.Sheets {
-		if sheet.Name == sheetName {
-			ok = true
-			break
-		}
+	target, ok, err := reader.parts.hyperlink(reader.Sheet, reader.ref(col))
+
+	if err != nil {
+		return nil, gserrors.Newf(err, "can't read hyperlink of cell[%s]", reader.cell(col))
 	}
 
 	if !ok {
-		return nil
+		return cell, nil
 	}
 
-	if len(sheet.Rows) < 2 {
-		return nil
+	return &x.Cell{Value: target}, nil
+}
+
+// comment get the cell of comment text for the "comment" tag option, the cell without
+// comment is empty, so is every cell of the workbook built in memory
+func (reader *RowReader) comment(col *column) (*x.Cell, error) {
+
+	if reader.parts == nil {
+		return &x.Cell{}, nil
 	}
 
-	header := sheet.Rows[0]
+	text, err := reader.parts.comment(reader.Sheet, reader.ref(col))
 
-	rows = make([]*RowReader, len(sheet.Rows)-1)
+	if err != nil {
+		return nil, gserrors.Newf(err, "can't read comment of cell[%s]", reader.cell(col))
+	}
+
+	return &x.Cell{Value: text}, nil
+}
 
-	for i, row := range sheet.Rows[1:] {
-		rows[i] = reader.newRowReader(sheetName, header, row, i)
+// textValue get the displayed text of cell for the "text" tag option, the numeric cells
+// are formatted by the number format, e.g. 1234 with format "00000" is "01234"
+func textValue(cell *x.Cell) string {
+
+	if cell.Type() != x.CellTypeNumeric {
+		return cell.Value
 	}
 
-	return
+	// the zero padded format of zip codes, e.g. "00000", isn't rendered by tealeg/xlsx
+	if format := cell.NumFmt; format != "" && strings.Trim(format, "0") == "" {
+		if f, err := cell.Float(); err == nil && f >= 0 && f == math.Trunc(f) && f < 1<<53 {
+			val := strconv.FormatFloat(f, 'f', -1, 64)
+
+			if len(val) < len(format) {
+				val = strings.Repeat("0", len(format)-len(val)) + val
+			}
+
+			return val
+		}
+	}
+
+	val, err := cell.FormattedValue()
+
+	if err != nil {
+		return cell.Value
+	}
+
+	return val
+}
+
+// cell get the cell description of column for error messages, e.g. "Sheet1.Count:2(A4)",
+// which contains the column key, data row id and the excel A1 reference
+func (reader *RowReader) cell(col *column) string {
+	return fmt.Sprintf("%s:%d(%s)", col.key, reader.id, reader.ref(col))
+}
+
+// ref get the excel A1 reference of column cell in current row
+func (reader *RowReader) ref(col *column) string {
+	return x.GetCellIDStringFromCoords(col.index, reader.rownum)
+}
+
+// fieldName resolve the struct field name of column, the header name is first
+// transformed by NameFunc, then the xlsx struct tag wins and NameMapping is
+// fallback, then the case insensitive matching if enabled.
+// return false if the field is tagged with "-" or the header name is empty, e.g. spacer columns
+func (reader *RowReader) fieldName(structType reflect.Type, colname string) (string, bool) {
+
+	if reader.nameFunc != nil {
+		colname = reader.nameFunc(colname)
+	}
+
+	if strings.TrimSpace(colname) == "" {
+		return "", false
+	}
+
+	for _, field := range columnFields(structType) {
+		if name, _ := fieldTag(field); name != "-" && name == colname {
+			return field.Name, true
+		}
+	}
+
+	if name, ok := reader.nameMapping[fmt.Sprintf("%s.%s", reader.Sheet, colname)]; ok {
+		colname = name
+	}
+
+	if field, ok := structType.FieldByName(colname); ok {
+		if name, _ := fieldTag(field); name == "-" {
+			return "", false
+		}
+
+		return colname, true
+	}
+
+	if reader.caseInsensitive {
+		if name, ok := foldedFields(reader.foldedFields, structType)[foldName(colname)]; ok {
+			return name, true
+		}
+	}
+
+	return colname, true
+}
+
+// readTime read time.Time field, excel date cells are converted from the serial number,
+// other cells are parsed by layout or the reader's default TimeLayout
+func (reader *RowReader) readTime(col *column, cell *x.Cell, assign reflect.Value) error {
+
+	if cell.Value == "" {
+		assign.Set(reflect.Zero(timeType))
+		return nil
+	}
+
+	if cell.Type() == x.CellTypeNumeric && cell.IsTime() {
+		t, err := cell.GetTime(reader.date1904)
+
+		if err != nil {
+			return gserrors.Newf(err, "can't conv cell[%s] '%s' to time", reader.cell(col), cell.Value)
+		}
+
+		// excel serial date only keeps millisecond precision
+		assign.Set(reflect.ValueOf(t.Round(time.Millisecond)))
+		return nil
+	}
+
+	layout := col.opts.Get("layout")
+
+	if layout == "" {
+		layout = reader.timeLayout
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	// the candidate layouts of mixed format column are separated by '|' and tried in order
+	layouts := strings.Split(layout, "|")
+
+	val := reader.trim(cell.Value, reflect.Struct)
+
+	var err error
+
+	for _, layout := range layouts {
+
+		var t time.Time
+
+		if t, err = time.Parse(layout, val); err == nil {
+			assign.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+
+	if len(layouts) > 1 {
+		return gserrors.Newf(nil, "can't conv cell[%s] '%s' to time, tried layouts %s", reader.cell(col), cell.Value, strings.Join(layouts, ", "))
+	}
+
+	return gserrors.Newf(err, "can't conv cell[%s] '%s' to time", reader.cell(col), cell.Value)
+}
+
+// readDuration read time.Duration value by time.ParseDuration, e.g. "1h30m", the bare
+// number is read as nanoseconds if DurationNanoseconds is enabled
+func (reader *RowReader) readDuration(col *column, val string, assign reflect.Value) error {
+
+	if reader.durationNanoseconds {
+		if v, err := strconv.ParseInt(val, 10, 64); err == nil {
+			assign.SetInt(v)
+			return nil
+		}
+	}
+
+	v, err := time.ParseDuration(val)
+
+	if err != nil {
+		return gserrors.Newf(err, "can't conv cell[%s] '%s' to duration", reader.cell(col), val)
+	}
+
+	assign.SetInt(int64(v))
+
+	return nil
+}
+
+// readBig read big.Int or big.Float value or pointer, the big.Float precision is enough
+// to keep all the decimal digits of val and at least 64 bits. empty cell is read as zero
+// value or nil pointer
+func (reader *RowReader) readBig(col *column, val string, assign reflect.Value) error {
+
+	if val == "" {
+		assign.Set(reflect.Zero(assign.Type()))
+		return nil
+	}
+
+	if assign.Kind() == reflect.Ptr {
+		assign.Set(reflect.New(assign.Type().Elem()))
+		assign = assign.Elem()
+	}
+
+	if assign.Type() == bigIntType {
+		v, ok := new(big.Int).SetString(val, 0)
+
+		if !ok {
+			return gserrors.Newf(nil, "can't conv cell[%s] '%s' to big.Int", reader.cell(col), val)
+		}
+
+		assign.Set(reflect.ValueOf(v).Elem())
+
+		return nil
+	}
+
+	prec := uint(math.Ceil(float64(len(val)) * math.Log2(10)))
+
+	if prec < 64 {
+		prec = 64
+	}
+
+	v, _, err := big.ParseFloat(val, 10, prec, big.ToNearestEven)
+
+	if err != nil {
+		return gserrors.Newf(err, "can't conv cell[%s] '%s' to big.Float", reader.cell(col), val)
+	}
+
+	assign.Set(reflect.ValueOf(v).Elem())
+
+	return nil
+}
+
+// readIP read net.IP by net.ParseIP, empty cell is read as nil
+func (reader *RowReader) readIP(col *column, val string, assign reflect.Value) error {
+
+	if val == "" {
+		assign.Set(reflect.Zero(ipType))
+		return nil
+	}
+
+	ip := net.ParseIP(val)
+
+	if ip == nil {
+		return gserrors.Newf(nil, "can't conv cell[%s] '%s' to net.IP", reader.cell(col), val)
+	}
+
+	assign.Set(reflect.ValueOf(ip))
+
+	return nil
+}
+
+// readURL read url.URL or *url.URL by url.Parse, empty cell is read as zero value or nil pointer
+func (reader *RowReader) readURL(col *column, val string, assign reflect.Value) error {
+
+	if val == "" {
+		assign.Set(reflect.Zero(assign.Type()))
+		return nil
+	}
+
+	u, err := url.Parse(val)
+
+	if err != nil {
+		return gserrors.Newf(err, "can't conv cell[%s] '%s' to url.URL", reader.cell(col), val)
+	}
+
+	if assign.Kind() == reflect.Ptr {
+		assign.Set(reflect.ValueOf(u))
+	} else {
+		assign.Set(reflect.ValueOf(u).Elem())
+	}
+
+	return nil
+}
+
+// readBuiltinType read builtin kind value, return false if the kind is not supported
+func (reader *RowReader) readBuiltinType(col *column, val string, assign reflect.Value) (bool, error) {
+
+	val = reader.trim(val, leafKind(assign.Type()))
+
+	if f, ok := reader.types[assign.Type()]; ok {
+		if err := f(assign, val); err != nil {
+			return true, gserrors.Newf(err, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+		}
+
+		return true, nil
+	}
+
+	// big.Int and big.Float are TextUnmarshaler, but the text of big.Float is parsed in 64 bits
+	if isBigType(assign.Type()) {
+		return true, reader.readBig(col, val, assign)
+	}
+
+	if assign.Type() == ipType {
+		return true, reader.readIP(col, val, assign)
+	}
+
+	if assign.Type() == urlType || assign.Type() == reflect.PointerTo(urlType) {
+		return true, reader.readURL(col, val, assign)
+	}
+
+	if ok, err := reader.readText(col, val, assign); ok {
+		return true, err
+	}
+
+	if ok, err := reader.readJSON(col, val, assign); ok {
+		return true, err
+	}
+
+	if reader.emptyAsZero && isScalarKind(assign.Kind()) && strings.TrimSpace(val) == "" {
+		assign.Set(reflect.Zero(assign.Type()))
+		return true, nil
+	}
+
+	if assign.Type() == durationType {
+		return true, reader.readDuration(col, val, assign)
+	}
+
+	switch assign.Type().Kind() {
+	case reflect.Bool:
+		v, err := reader.parseBool(col, val)
+
+		if err != nil {
+			return true, err
+		}
+
+		assign.SetBool(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val = reader.number(col, val)
+
+		v, err := parseInt(val, assign.Type().Bits())
+
+		if err != nil {
+			return true, gserrors.Newf(err, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+		}
+
+		assign.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val = reader.number(col, val)
+
+		if n, err := parseInt(val, 64); err == nil && n < 0 {
+			return true, gserrors.Newf(nil, "can't conv cell[%s]: negative value %d into unsigned field %s", reader.cell(col), n, col.name)
+		}
+
+		v, err := parseUint(val, assign.Type().Bits())
+
+		if err != nil {
+			return true, gserrors.Newf(err, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+		}
+
+		assign.SetUint(v)
+
+	case reflect.Float32, reflect.Float64:
+		val = reader.number(col, val)
+
+		v, err := strconv.ParseFloat(val, assign.Type().Bits())
+
+		if err != nil {
+			return true, gserrors.Newf(err, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+		}
+
+		if reader.rejectNonFinite && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			return true, gserrors.Newf(nil, "can't conv cell[%s] '%s' to %s, non-finite number", reader.cell(col), val, assign.Type())
+		}
+
+		assign.SetFloat(v)
+
+	case reflect.Complex64, reflect.Complex128:
+
+		v, err := strconv.ParseComplex(val, assign.Type().Bits())
+
+		if err != nil {
+			return true, gserrors.Newf(err, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+		}
+
+		assign.SetComplex(v)
+
+	case reflect.String:
+		assign.SetString(val)
+	case reflect.Array:
+
+		assign.Set(reflect.Zero(assign.Type()))
+
+		if val == "" {
+			break
+		}
+
+		subs := reader.split(col, val)
+
+		if len(subs) > assign.Len() {
+			return true, gserrors.Newf(nil, "can't conv cell[%s] '%s', array length(%d) overflow", reader.cell(col), val, assign.Len())
+		}
+
+		for i, sub := range subs {
+			ok, err := reader.readBuiltinType(col, sub, assign.Index(i))
+
+			if !ok {
+				return true, gserrors.Newf(nil, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+			}
+
+			if err != nil {
+				return true, err
+			}
+		}
+
+	case reflect.Map:
+		return true, reader.readMap(col, val, assign)
+
+	case reflect.Slice:
+
+		if assign.Type().Elem().Kind() == reflect.Uint8 {
+			return true, reader.readBytes(col, val, assign)
+		}
+
+		if _, ok := reader.types[assign.Type().Elem()]; ok || isScalarKind(assign.Type().Elem().Kind()) {
+			return true, reader.readScalarSlice(col, val, assign)
+		}
+
+		pattern, err := reader.columnPattern(col)
+
+		if err != nil {
+			return true, err
+		}
+
+		subs := reader.split(col, val)
+
+		slice := reflect.MakeSlice(assign.Type(), 0, len(subs))
+
+		subType := assign.Type().Elem()
+
+		if subType.Kind() == reflect.Ptr {
+			subType = subType.Elem()
+		}
+
+		fields := subexpFields(pattern, subType)
+
+		for _, sub := range subs {
+			matched := pattern.FindStringSubmatch(sub)
+
+			if matched == nil {
+
+				if sub != "" {
+					return true, gserrors.Newf(nil, "can't conv cell[%s] '%s'", reader.cell(col), val)
+				}
+
+				continue
+			}
+
+			subval := reflect.New(subType)
+
+			for i, match := range matched[1:] {
+
+				if match == "" || fields[i] < 0 {
+					continue
+				}
+
+				field := subType.Field(fields[i])
+
+				sub := &column{
+					index: col.index,
+					name:  field.Name,
+					key:   fmt.Sprintf("%s.%s", col.key, field.Name),
+				}
+
+				if _, err := reader.readBuiltinType(sub, match, reflect.Indirect(subval).Field(fields[i])); err != nil {
+					return true, err
+				}
+			}
+
+			if assign.Type().Elem().Kind() == reflect.Ptr {
+				slice = reflect.Append(slice, subval)
+			} else {
+				slice = reflect.Append(slice, reflect.Indirect(subval))
+			}
+		}
+
+		assign.Set(slice)
+
+	case reflect.Struct:
+
+		index, ok := nullValueField(assign.Type())
+
+		if !ok {
+			return false, nil
+		}
+
+		assign.Set(reflect.Zero(assign.Type()))
+
+		if strings.TrimSpace(val) == "" {
+			break
+		}
+
+		if ok, err := reader.readBuiltinType(col, val, assign.Field(index)); !ok || err != nil {
+			return ok, err
+		}
+
+		assign.FieldByName("Valid").SetBool(true)
+
+	case reflect.Ptr:
+
+		if val == "" {
+			assign.Set(reflect.Zero(assign.Type()))
+			break
+		}
+
+		elem := reflect.New(assign.Type().Elem())
+
+		ok, err := reader.readBuiltinType(col, val, elem.Elem())
+
+		if !ok || err != nil {
+			return ok, err
+		}
+
+		assign.Set(elem)
+
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// trim trim the cell value or slice token according to TrimSpace and TrimStrings options,
+// the string kind value is only trimmed by TrimStrings, slice and array values are
+// trimmed token by token after splitting
+func (reader *RowReader) trim(val string, kind reflect.Kind) string {
+
+	if kind == reflect.Slice || kind == reflect.Array {
+		return val
+	}
+
+	if kind == reflect.String {
+		if reader.trimStrings {
+			return strings.TrimSpace(val)
+		}
+
+		return val
+	}
+
+	if reader.trimSpace {
+		return strings.TrimSpace(val)
+	}
+
+	return val
+}
+
+// parseInt parse the decimal integer or the hexadecimal one with "0x" prefix, the leading
+// zeros are decimal, e.g. zip code "010" is 10, not octal 8
+func parseInt(val string, bits int) (int64, error) {
+
+	sign, digits := "", val
+
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	if hex, ok := hexDigits(digits); ok {
+		return strconv.ParseInt(sign+hex, 16, bits)
+	}
+
+	return strconv.ParseInt(val, 10, bits)
+}
+
+// parseUint parse the unsigned integer like parseInt
+func parseUint(val string, bits int) (uint64, error) {
+
+	if hex, ok := hexDigits(val); ok {
+		return strconv.ParseUint(hex, 16, bits)
+	}
+
+	return strconv.ParseUint(val, 10, bits)
+}
+
+// hexDigits get the digits after "0x" or "0X" prefix
+func hexDigits(val string) (string, bool) {
+
+	if len(val) > 2 && val[0] == '0' && (val[1] == 'x' || val[1] == 'X') && val[2] != '-' && val[2] != '+' {
+		return val[2:], true
+	}
+
+	return "", false
+}
+
+// leafKind get the kind of value read through pointers and nullable structs, e.g. the
+// string kind of *string and sql.NullString, which decides the trimming of cell value
+func leafKind(t reflect.Type) reflect.Kind {
+
+	for {
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+		case reflect.Struct:
+			index, ok := nullValueField(t)
+
+			if !ok {
+				return t.Kind()
+			}
+
+			t = t.Field(index).Type
+		default:
+			return t.Kind()
+		}
+	}
+}
+
+// separator get the split chars of column, the xlsx tag "split:" option wins,
+// then the Reader.Splits keyed by column, then the row reader's Split
+func (reader *RowReader) separator(col *column) string {
+
+	if split := col.opts.Get("split"); split != "" {
+		return split
+	}
+
+	if split, ok := reader.splits[col.key]; ok {
+		return split
+	}
+
+	// the numbers of ',' decimal locale can't be split by the default ','
+	if reader.decimalSeparator != 0 && reader.Split == string(reader.decimalSeparator) {
+		return ";"
+	}
+
+	return reader.Split
+}
+
+// split split the cell value of slice, array or map column by separator, the default
+// Split is replaced by SplitRegexp if set
+func (reader *RowReader) split(col *column, val string) []string {
+
+	if reader.splitRegexp != nil && col.opts.Get("split") == "" {
+		if _, ok := reader.splits[col.key]; !ok {
+			return reader.splitRegexp.Split(val, -1)
+		}
+	}
+
+	return strings.Split(val, reader.separator(col))
+}
+
+// readText read field which implements encoding.TextUnmarshaler, return false if not implemented
+func (reader *RowReader) readText(col *column, val string, assign reflect.Value) (bool, error) {
+
+	var unmarshaler encoding.TextUnmarshaler
+
+	if assign.Kind() == reflect.Ptr && assign.Type().Implements(textUnmarshalerType) {
+
+		if val == "" {
+			assign.Set(reflect.Zero(assign.Type()))
+			return true, nil
+		}
+
+		if assign.IsNil() {
+			assign.Set(reflect.New(assign.Type().Elem()))
+		}
+
+		unmarshaler = assign.Interface().(encoding.TextUnmarshaler)
+
+	} else if assign.CanAddr() && reflect.PointerTo(assign.Type()).Implements(textUnmarshalerType) {
+		unmarshaler = assign.Addr().Interface().(encoding.TextUnmarshaler)
+	} else {
+		return false, nil
+	}
+
+	if err := unmarshaler.UnmarshalText([]byte(val)); err != nil {
+		return true, gserrors.Newf(err, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+	}
+
+	return true, nil
+}
+
+// readJSON unmarshal the json cell into field tagged with "json" option or implements
+// json.Unmarshaler, return false if neither. empty cell is read as zero value
+func (reader *RowReader) readJSON(col *column, val string, assign reflect.Value) (bool, error) {
+
+	if !col.opts.Has("json") && !reflect.PointerTo(assign.Type()).Implements(jsonUnmarshalerType) {
+		return false, nil
+	}
+
+	if strings.TrimSpace(val) == "" {
+		assign.Set(reflect.Zero(assign.Type()))
+		return true, nil
+	}
+
+	v := reflect.New(assign.Type())
+
+	if err := json.Unmarshal([]byte(val), v.Interface()); err != nil {
+		return true, gserrors.Newf(err, "can't conv cell[%s] '%s' to %s", reader.cell(col), val, assign.Type())
+	}
+
+	assign.Set(v.Elem())
+
+	return true, nil
+}
+
+// columnPattern get the subtype pattern of column, the Pattern wins, then the PatternStr
+// which is compiled once and cached by the pattern string
+func (reader *RowReader) columnPattern(col *column) (*regexp.Regexp, error) {
+
+	if pattern, ok := reader.pattern[col.key]; ok {
+		return pattern, nil
+	}
+
+	expr, ok := reader.patternStr[col.key]
+
+	if !ok {
+		return nil, gserrors.Newf(nil, "can't conv cell[%s], not found convert pattern", reader.cell(col))
+	}
+
+	if pattern, ok := reader.patterns.Load(expr); ok {
+		return pattern.(*regexp.Regexp), nil
+	}
+
+	pattern, err := regexp.Compile(expr)
+
+	if err != nil {
+		return nil, gserrors.Newf(err, "invalid pattern of column %s: %s", col.key, expr)
+	}
+
+	cached, _ := reader.patterns.LoadOrStore(expr, pattern)
+
+	return cached.(*regexp.Regexp), nil
+}
+
+// readScalarSlice read slice of builtin scalar kind or registered type, no convert pattern is required
+func (reader *RowReader) readScalarSlice(col *column, val string, assign reflect.Value) error {
+
+	if val == "" {
+		assign.Set(reflect.MakeSlice(assign.Type(), 0, 0))
+		return nil
+	}
+
+	subs := reader.split(col, val)
+
+	slice := reflect.MakeSlice(assign.Type(), len(subs), len(subs))
+
+	for i, sub := range subs {
+		if _, err := reader.readBuiltinType(col, sub, slice.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	assign.Set(slice)
+
+	return nil
+}
+
+// readBytes decode the base64 cell into []byte field by Reader.Base64, default base64.StdEncoding
+func (reader *RowReader) readBytes(col *column, val string, assign reflect.Value) error {
+
+	encoding := reader.base64
+
+	if encoding == nil {
+		encoding = base64.StdEncoding
+	}
+
+	data, err := encoding.DecodeString(val)
+
+	if err != nil {
+		return gserrors.Newf(err, "can't conv cell[%s] '%s' to %s, invalid base64", reader.cell(col), val, assign.Type())
+	}
+
+	assign.SetBytes(data)
+
+	return nil
+}
+
+// readMap read "k1:v1,k2:v2" cell into map, the pairs are split by the column separator
+// and the key value split chars of pair is Reader.KeySplit. empty cell is read as empty map
+func (reader *RowReader) readMap(col *column, val string, assign reflect.Value) error {
+
+	m := reflect.MakeMap(assign.Type())
+
+	if val != "" {
+
+		keySplit := reader.keySplit
+
+		if keySplit == "" {
+			keySplit = ":"
+		}
+
+		for _, pair := range reader.split(col, val) {
+
+			kv := strings.SplitN(pair, keySplit, 2)
+
+			if len(kv) != 2 {
+				return gserrors.Newf(nil, "can't conv cell[%s] '%s' to %s, expect key%svalue pair", reader.cell(col), pair, assign.Type(), keySplit)
+			}
+
+			key := reflect.New(assign.Type().Key()).Elem()
+
+			if ok, err := reader.readBuiltinType(col, kv[0], key); !ok {
+				return gserrors.Newf(nil, "can't conv cell[%s], unsupported map key type %s", reader.cell(col), key.Type())
+			} else if err != nil {
+				return err
+			}
+
+			elem := reflect.New(assign.Type().Elem()).Elem()
+
+			if ok, err := reader.readBuiltinType(col, kv[1], elem); !ok {
+				return gserrors.Newf(nil, "can't conv cell[%s], unsupported map value type %s", reader.cell(col), elem.Type())
+			} else if err != nil {
+				return err
+			}
+
+			m.SetMapIndex(key, elem)
+		}
+	}
+
+	assign.Set(m)
+
+	return nil
+}
+
+// number normalize the formatted number of string cell if FormattedNumbers is enabled or
+// the separators are set, the stored numbers of numeric cells are untouched. the thousands
+// separators are stripped, the decimal separator is replaced with '.' and the value with
+// trailing '%' is divided by 100 if PercentAsFraction, e.g. "1,234.5" => "1234.5", "1.5%" => "0.015"
+func (reader *RowReader) number(col *column, val string) string {
+
+	if col.numeric || !reader.formattedNumbers && reader.decimalSeparator == 0 && reader.thousandsSeparator == 0 {
+		return val
+	}
+
+	decimal, thousands := reader.decimalSeparator, reader.thousandsSeparator
+
+	if decimal == 0 {
+		decimal = '.'
+	}
+
+	// the ',' decimal locale groups thousands with '.'
+	if thousands == 0 && decimal == ',' {
+		thousands = '.'
+	} else if thousands == 0 {
+		thousands = ','
+	}
+
+	percent := reader.formattedNumbers && strings.HasSuffix(val, "%")
+
+	if percent {
+		val = strings.TrimSpace(strings.TrimSuffix(val, "%"))
+	}
+
+	// the percent is kept whole without PercentAsFraction, "12%" is read as 12
+	percent = percent && reader.percentAsFraction
+
+	// the decimal separator wins if both separators are the same rune
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case decimal:
+			return '.'
+		case thousands:
+			return -1
+		}
+
+		return r
+	}, val)
+
+	if !percent {
+		return normalized
+	}
+
+	v, err := strconv.ParseFloat(normalized, 64)
+
+	if err != nil {
+		return val + "%"
+	}
+
+	return strconv.FormatFloat(v/100, 'f', -1, 64)
+}
+
+// isNull check if the cell value is one of NullValues ignoring case
+func (reader *RowReader) isNull(val string) bool {
+
+	for _, null := range reader.nullValues {
+		if strings.EqualFold(strings.TrimSpace(val), null) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseBool parse bool cell value, TrueValues and FalseValues are matched ignoring case,
+// if any of them is set the value must be one of the known values
+func (reader *RowReader) parseBool(col *column, val string) (bool, error) {
+
+	// the empty bool cell is an error like the empty number without EmptyAsZero
+	if !reader.emptyAsZero && strings.TrimSpace(val) == "" {
+		return false, gserrors.Newf(nil, "can't conv cell[%s] '%s' to bool", reader.cell(col), val)
+	}
+
+	for _, v := range reader.trueValues {
+		if strings.EqualFold(val, v) {
+			return true, nil
+		}
+	}
+
+	for _, v := range reader.falseValues {
+		if strings.EqualFold(val, v) {
+			return false, nil
+		}
+	}
+
+	switch strings.ToLower(val) {
+	case "true", "1":
+		return true, nil
+	case "false", "0", "":
+		return false, nil
+	}
+
+	if reader.trueValues != nil || reader.falseValues != nil {
+		return false, gserrors.Newf(nil, "can't conv cell[%s] '%s' to bool", reader.cell(col), val)
+	}
+
+	return false, nil
+}
+
+// isIntKind check if kind is signed or unsigned integer
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// isBigType check if t is big.Int, big.Float or the pointer of them
+func isBigType(t reflect.Type) bool {
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == bigIntType || t == bigFloatType
+}
+
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+
+	return false
+}
+
+// Reader xlsx reader
+type Reader struct {
+	gslogger.Log                                         // mixin log
+	file                     *x.File                     // xlsx file
+	date1904                 bool                        // workbook date system, kept for the rows read before Close
+	parts                    *parts                      // workbook parts dropped by tealeg, e.g. hyperlinks and comments, nil for the in memory file
+	Pattern                  map[string]*regexp.Regexp   // subtype pattern
+	PatternStr               map[string]string           // subtype pattern string compiled on first use, Pattern takes precedence
+	Splits                   map[string]string           // split chars by "Sheet.Column", override the default ","
+	Unmarshalers             map[string]UnmarshalF       // unmarshal functions
+	types                    map[reflect.Type]UnmarshalF // unmarshal functions by type
+	NameMapping              map[string]string           // name mapping
+	TimeLayout               string                      // time layout for non date cells, default RFC3339
+	CollectErrors            bool                        // collect all cell errors of row into MultiError
+	ContinueOnError          bool                        // ReadAll, ReadKeyed and ReadParallel skip the bad rows and return the good rows with the error
+	HeaderRow                int                         // zero based header row index, data begins at HeaderRow+HeaderRows+SkipRows
+	HeaderRows               int                         // count of header rows joined into composite column names, default 1
+	HeaderSeparator          string                      // separator of composite column names, default " "
+	SkipRows                 int                         // rows skipped after the header before data begins, e.g. the units row
+	Headerless               bool                        // sheet has no header, all rows are data and fields are mapped by zero based `xlsx:"col:N"` tag
+	SkipBlankRows            bool                        // skip rows whose cells are all empty
+	EmptyAsZero              bool                        // read empty numeric and bool cells as zero value instead of error, default true
+	FillDown                 []string                    // columns whose blank cells are filled with the last non-empty value above
+	FillDownAll              bool                        // fill down all columns
+	TrimSpace                bool                        // trim cell values and slice tokens before conversion, except string values
+	TrimStrings              bool                        // trim string values, string columns may be whitespace significant
+	TrueValues               []string                    // extra values read as true ignoring case, e.g. "yes", "on"
+	FalseValues              []string                    // extra values read as false ignoring case, unknown values are error if any of the two is set
+	NullValues               []string                    // sentinel values read as empty cell ignoring case, e.g. "NULL", "N/A"
+	ErrorCells               ErrorCellPolicy             // handling of excel error cells like "#DIV/0!", default ErrorCellAsError
+	FormattedNumbers         bool                        // parse numbers with thousands separators and trailing '%', e.g. "1,000", "12.5%"
+	DecimalSeparator         rune                        // decimal separator of numbers, default '.', the default Split equal to it falls back to ";"
+	ThousandsSeparator       rune                        // thousands separator of numbers, default ',', or '.' if DecimalSeparator is ','
+	DurationNanoseconds      bool                        // read bare number of time.Duration field as nanoseconds instead of error
+	PreferCachedValue        bool                        // read formula cells as the cached result, otherwise as formula text like "=A1+B1", default true
+	RejectNonFinite          bool                        // error on float cells parsed to NaN or Inf, e.g. "NaN", "+Inf"
+	PercentAsFraction        bool                        // read percent cells and "12%" formatted numbers as the fraction 0.12, otherwise as the whole percent 12, default true
+	KeySplit                 string                      // key value split chars of map cells like "k1:v1,k2:v2", default ":"
+	SplitRegexp              *regexp.Regexp              // separator of slice, array and map cells replacing the default ",", e.g. `\s*[,;]\s*`
+	Base64                   *base64.Encoding            // encoding of []byte cells, default base64.StdEncoding
+	DisallowUnknownColumns   bool                        // error on header columns which can't be mapped to field
+	RequireAllFields         bool                        // error on struct fields which are not mapped by any header column
+	DisallowDuplicateColumns bool                        // error on header columns mapped to the same field, otherwise the first column wins
+	CaseInsensitive          bool                        // match columns to fields ignoring case, spaces and underscores
+	NameFunc                 func(header string) string  // transform header name before tag, NameMapping and field matching
+	Progress                 func(done, total int)       // called every progressRows rows converted by ReadAll, ReadKeyed, ReadParallel or Validate and at the end
+	headers                  map[string][]string         // header names supplied by SetHeader
+	foldedFields             sync.Map                    // folded field name index cache, map[reflect.Type]map[string]string
+	patterns                 sync.Map                    // compiled PatternStr cache, map[string]*regexp.Regexp
+}
+
+// NewReader create new xlsx file reader
+func NewReader(filename string) (*Reader, error) {
+	file, err := x.OpenFile(filename)
+
+	if err != nil {
+		return nil, gserrors.Newf(err, "create new xlsx reader error :%s", filename)
+	}
+
+	reader := newReader(file)
+
+	// the file is read again only if the hyperlinks or comments are read
+	reader.parts = newParts(func() ([]byte, error) {
+		return os.ReadFile(filename)
+	})
+
+	return reader, nil
+}
+
+// NewReaderFromBinary create new xlsx reader from in memory file content
+func NewReaderFromBinary(data []byte) (*Reader, error) {
+	file, err := x.OpenBinary(data)
+
+	if err != nil {
+		return nil, gserrors.Newf(err, "create new xlsx reader from binary error")
+	}
+
+	reader := newReader(file)
+
+	reader.parts = newParts(func() ([]byte, error) {
+		return data, nil
+	})
+
+	return reader, nil
+}
+
+// NewReaderFromReader create new xlsx reader from io.Reader, the content is read into memory
+func NewReaderFromReader(r io.Reader) (*Reader, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, gserrors.Newf(err, "create new xlsx reader from io.Reader error")
+	}
+
+	return NewReaderFromBinary(data)
+}
+
+// Close release the workbook held by reader, the file opened by NewReader is closed
+// once loaded, so Close only drops the in memory workbook and never fails. after close
+// the reads return ErrClosed, or nil like the sheet not found if no error is returned
+func (reader *Reader) Close() error {
+	reader.file = nil
+	reader.parts = nil
+
+	return nil
+}
+
+// RegisterType register unmarshal function for all fields of type t, the function
+// is called with the field value. the Unmarshalers keyed by column take precedence
+func (reader *Reader) RegisterType(t reflect.Type, f UnmarshalF) {
+	if reader.types == nil {
+		reader.types = make(map[reflect.Type]UnmarshalF)
+	}
+
+	reader.types[t] = f
+}
+
+// SetHeader supply the column names of sheet in order instead of the header rows, all rows
+// after SkipRows are data and HeaderRow, HeaderRows are ignored for the sheet. nil names
+// restore the header of sheet
+func (reader *Reader) SetHeader(sheet string, names []string) {
+
+	if names == nil {
+		delete(reader.headers, sheet)
+		return
+	}
+
+	if reader.headers == nil {
+		reader.headers = make(map[string][]string)
+	}
+
+	reader.headers[sheet] = names
+}
+
+// RegisterEnum register the string names of integer enum type t, the cell value is
+// looked up in mapping and the unknown name is an error listing the valid names
+func (reader *Reader) RegisterEnum(t reflect.Type, mapping map[string]int64) {
+
+	names := make([]string, 0, len(mapping))
+
+	for name := range mapping {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	reader.RegisterType(t, func(val reflect.Value, cell string) error {
+
+		num, ok := mapping[cell]
+
+		if !ok {
+			return gserrors.Newf(nil, "unknown %s value '%s', valid values: %s", t, cell, strings.Join(names, ", "))
+		}
+
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			val.SetInt(num)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			val.SetUint(uint64(num))
+		default:
+			return gserrors.Newf(nil, "enum type %s is not integer", t)
+		}
+
+		return nil
+	})
+}
+
+func newReader(file *x.File) *Reader {
+	return &Reader{
+		Log:               gslogger.Get("xlsx"),
+		file:              file,
+		date1904:          file.Date1904,
+		TimeLayout:        time.RFC3339,
+		EmptyAsZero:       true,
+		PreferCachedValue: true,
+		PercentAsFraction: true,
+	}
+}
+
+// Read read all rows, return nil if the sheet not found
+func (reader *Reader) Read(sheetName string) (rows []*RowReader) {
+
+	if !reader.HasSheet(sheetName) {
+		return nil
+	}
+
+	rows, err := reader.ReadSheet(sheetName)
+
+	if err != nil {
+		reader.E("read sheet %s error :%s", sheetName, err)
+		return nil
+	}
+
+	return
+}
+
+// ReadSheet read all rows, return ErrSheetNotFound if the sheet not found
+func (reader *Reader) ReadSheet(sheetName string) ([]*RowReader, error) {
+
+	sheet, err := reader.lookupSheet(sheetName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reader.readRows(context.Background(), sheet)
+}
+
+// ReadIndex read all rows of the i-th sheet, return nil if the index out of range
+func (reader *Reader) ReadIndex(i int) []*RowReader {
+
+	if reader.file == nil || i < 0 || i >= len(reader.file.Sheets) {
+		return nil
+	}
+
+	rows, err := reader.readRows(context.Background(), reader.file.Sheets[i])
+
+	if err != nil {
+		reader.E("read sheet(%d) error :%s", i, err)
+		return nil
+	}
+
+	return rows
+}
+
+// ReadAllSheets read rows of all sheets keyed by sheet name, the sheets without data row are skipped
+func (reader *Reader) ReadAllSheets() map[string][]*RowReader {
+
+	sheets := make(map[string][]*RowReader)
+
+	for _, name := range reader.SheetNames() {
+		if rows := reader.Read(name); len(rows) != 0 {
+			sheets[name] = rows
+		}
+	}
+
+	return sheets
+}
+
+// SheetNames get all sheet names in workbook order
+func (reader *Reader) SheetNames() []string {
+
+	if reader.file == nil {
+		return nil
+	}
+
+	names := make([]string, len(reader.file.Sheets))
+
+	for i, sheet := range reader.file.Sheets {
+		names[i] = sheet.Name
+	}
+
+	return names
+}
+
+// HasSheet check if the sheet exists
+func (reader *Reader) HasSheet(name string) bool {
+	return reader.sheet(name) != nil
+}
+
+// RowCount get the number of data rows after the header and SkipRows without reading
+// them, the blank rows are counted even if SkipBlankRows is enabled. return -1 if the
+// sheet not found
+func (reader *Reader) RowCount(name string) int {
+
+	sheet := reader.sheet(name)
+
+	if sheet == nil {
+		return -1
+	}
+
+	if count := len(sheet.Rows) - reader.dataRow(sheet.Name, 0); count > 0 {
+		return count
+	}
+
+	return 0
+}
+
+// File get the underlying tealeg file for the workbook features not wrapped by Reader,
+// e.g. defined names and styles. mutating the file may affect the rows read after
+func (reader *Reader) File() *x.File {
+	return reader.file
+}
+
+// Sheet get the underlying tealeg sheet, return nil if the sheet not found. like File,
+// mutating the sheet may affect the rows read after
+func (reader *Reader) Sheet(name string) *x.Sheet {
+	return reader.sheet(name)
+}
+
+func (reader *Reader) sheet(name string) *x.Sheet {
+
+	if reader.file == nil {
+		return nil
+	}
+
+	for _, sheet := range reader.file.Sheets {
+		if sheet.Name == name {
+			return sheet
+		}
+	}
+
+	return nil
+}
+
+// lookupSheet get the sheet by name, return ErrClosed if the reader is closed or
+// ErrSheetNotFound if the sheet not found
+func (reader *Reader) lookupSheet(name string) (*x.Sheet, error) {
+
+	if reader.file == nil {
+		return nil, ErrClosed
+	}
+
+	if sheet := reader.sheet(name); sheet != nil {
+		return sheet, nil
+	}
+
+	return nil, &ErrSheetNotFound{name}
+}
+
+// ReadContext read all rows like ReadSheet, return ctx.Err() if the context is done
+// while reading, which is checked every contextCheckRows rows
+func (reader *Reader) ReadContext(ctx context.Context, sheetName string) ([]*RowReader, error) {
+
+	sheet, err := reader.lookupSheet(sheetName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reader.readRows(ctx, sheet)
+}
+
+// contextCheckRows the rows count between context checks
+const contextCheckRows = 1000
+
+// progressRows the rows count between Progress calls
+const progressRows = 1000
+
+// ReadRange read data rows in range [start, end), the indexes are relative to the first
+// data row, end <= 0 means to the end of sheet. return nil if the sheet not found or
+// the range is out of bounds
+func (reader *Reader) ReadRange(sheetName string, start, end int) []*RowReader {
+
+	sheet := reader.sheet(sheetName)
+
+	if sheet == nil {
+		return nil
+	}
+
+	rows, err := reader.readRange(context.Background(), sheet, start, end)
+
+	if err != nil {
+		reader.E("read sheet %s range [%d, %d) error :%s", sheetName, start, end, err)
+		return nil
+	}
+
+	return rows
+}
+
+// ReadFilter read rows like Read, only the rows which keep returns true are returned,
+// keep can inspect the raw cells by RowReader.Cell
+func (reader *Reader) ReadFilter(sheetName string, keep func(*RowReader) bool) (rows []*RowReader) {
+
+	for _, row := range reader.Read(sheetName) {
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	return
+}
+
+// Validate dry-run reading every row of sheet into a new value of v's type without keeping
+// the results, v must be struct or pointer to struct. the errors of all rows are returned
+// as MultiError of RowError, every row collects all of its cell errors regardless of CollectErrors
+func (reader *Reader) Validate(sheetName string, v interface{}) error {
+
+	structType := reflect.TypeOf(v)
+
+	if structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return &ErrInvalidUnmarshal{reflect.TypeOf(v)}
+	}
+
+	rows, err := reader.ReadSheet(sheetName)
+
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for i, row := range rows {
+
+		// all bad cells of row are reported, not only the first one
+		row.collectErrors = true
+
+		if err := row.Read(reflect.New(structType).Interface()); err != nil {
+			errs = append(errs, &RowError{Sheet: sheetName, Row: row.ID(), Err: err})
+		}
+
+		reader.progress(i+1, len(rows))
+	}
+
+	if len(errs) != 0 {
+		return &MultiError{errs}
+	}
+
+	return nil
+}
+
+// Stream send the rows of sheet to out one by one, out is closed when all rows are sent
+// or the read aborted. return ctx.Err() if the context is done before all rows are sent
+func (reader *Reader) Stream(ctx context.Context, sheetName string, out chan<- *RowReader) error {
+
+	defer close(out)
+
+	sheet, err := reader.lookupSheet(sheetName)
+
+	if err != nil {
+		return err
+	}
+
+	return reader.eachRow(ctx, sheet, 0, 0, func(row *RowReader) error {
+		select {
+		case out <- row:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// readRows create row readers of sheet's data rows, which begin after the HeaderRow
+func (reader *Reader) readRows(ctx context.Context, sheet *x.Sheet) (rows []*RowReader, err error) {
+	return reader.readRange(ctx, sheet, 0, 0)
+}
+
+// readRange create row readers of data rows in range [start, end), end <= 0 means to the end
+func (reader *Reader) readRange(ctx context.Context, sheet *x.Sheet, start, end int) (rows []*RowReader, err error) {
+
+	err = reader.eachRow(ctx, sheet, start, end, func(row *RowReader) error {
+		rows = append(rows, row)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// eachRow call f with row readers of data rows in range [start, end) one by one, end <= 0
+// means to the end, the rows before start are still visited for fill down. the iteration
+// stops at the first error of f or ctx.Err(), which is checked every contextCheckRows rows
+func (reader *Reader) eachRow(ctx context.Context, sheet *x.Sheet, start, end int, f func(*RowReader) error) error {
+
+	header, data, err := reader.splitRows(sheet)
+
+	if err != nil {
+		return err
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	if end <= 0 || end > len(data) {
+		end = len(data)
+	}
+
+	if start >= end {
+		return nil
+	}
+
+	mappings := &sync.Map{}
+
+	filler := reader.newFillDown(header)
+
+	for i, row := range data[:end] {
+
+		if i < start {
+			filler.fill(row)
+			continue
+		}
+
+		if i%contextCheckRows == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		// the reader closed while streaming stops the rows in flight
+		if reader.file == nil {
+			return ErrClosed
+		}
+
+		if !reader.SkipBlankRows || !isBlankRow(row) {
+
+			row = filler.fill(row)
+
+			if err := f(reader.newRowReader(sheet.Name, header, row, i, reader.dataRow(sheet.Name, i), mappings)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// progress call Progress if done reaches the next progressRows step or the total
+func (reader *Reader) progress(done, total int) {
+	if reader.Progress != nil && (done%progressRows == 0 || done == total) {
+		reader.Progress(done, total)
+	}
+}
+
+// splitRows split sheet rows into header row and data rows, the header of headerless
+// sheet is an empty row and the header set by SetHeader replaces the header rows
+func (reader *Reader) splitRows(sheet *x.Sheet) (header *x.Row, data []*x.Row, err error) {
+
+	if reader.SkipRows < 0 {
+		return nil, nil, gserrors.Newf(nil, "skip rows(%d) of sheet %s is negative", reader.SkipRows, sheet.Name)
+	}
+
+	if names, ok := reader.headers[sheet.Name]; ok {
+		return reader.suppliedHeader(sheet, names)
+	}
+
+	if reader.Headerless {
+		if len(sheet.Rows) <= reader.dataRow(sheet.Name, 0) {
+			return nil, nil, nil
+		}
+
+		return &x.Row{Sheet: sheet}, sheet.Rows[reader.dataRow(sheet.Name, 0):], nil
+	}
+
+	if reader.HeaderRow < 0 || (reader.HeaderRow > 0 && reader.HeaderRow >= len(sheet.Rows)) {
+		return nil, nil, gserrors.Newf(nil, "header row(%d) out of range, sheet %s has %d rows", reader.HeaderRow, sheet.Name, len(sheet.Rows))
+	}
+
+	if len(sheet.Rows) <= reader.dataRow(sheet.Name, 0) {
+		return nil, nil, nil
+	}
+
+	header = sheet.Rows[reader.HeaderRow]
+
+	if reader.HeaderRows > 1 {
+		header = reader.compositeHeader(sheet, sheet.Rows[reader.HeaderRow:reader.HeaderRow+reader.HeaderRows])
+	}
+
+	return trimHeader(header), sheet.Rows[reader.dataRow(sheet.Name, 0):], nil
+}
+
+// suppliedHeader split sheet rows with the header set by SetHeader, all rows after SkipRows
+// are data, the data rows can't have non-empty cells beyond the header
+func (reader *Reader) suppliedHeader(sheet *x.Sheet, names []string) (*x.Row, []*x.Row, error) {
+
+	if len(sheet.Rows) <= reader.dataRow(sheet.Name, 0) {
+		return nil, nil, nil
+	}
+
+	data := sheet.Rows[reader.dataRow(sheet.Name, 0):]
+
+	for i, row := range data {
+		if len(row.Cells) > len(names) && !isBlankRow(&x.Row{Cells: row.Cells[len(names):]}) {
+			return nil, nil, gserrors.Newf(nil, "row(%s:%d) has %d cells, wider than the %d columns of supplied header", sheet.Name, i, len(row.Cells), len(names))
+		}
+	}
+
+	header := &x.Row{Sheet: sheet}
+
+	for _, name := range names {
+		header.Cells = append(header.Cells, &x.Cell{Row: header, Value: name})
+	}
+
+	return header, data, nil
+}
+
+// trimHeader drop the trailing empty header cells, e.g. the phantom columns of deleted data,
+// the sheet row is not modified
+func trimHeader(header *x.Row) *x.Row {
+
+	width := len(header.Cells)
+
+	for width > 0 && strings.TrimSpace(header.Cells[width-1].Value) == "" {
+		width--
+	}
+
+	if width == len(header.Cells) {
+		return header
+	}
+
+	trimmed := *header
+
+	trimmed.Cells = header.Cells[:width]
+
+	return &trimmed
+}
+
+// compositeHeader join the values of header rows by HeaderSeparator into composite column
+// names, e.g. "2023" and "Q1" into "2023 Q1". the value of horizontally merged cell in
+// upper rows is filled across the merged columns, the empty values are skipped
+func (reader *Reader) compositeHeader(sheet *x.Sheet, rows []*x.Row) *x.Row {
+
+	separator := reader.HeaderSeparator
+
+	if separator == "" {
+		separator = " "
+	}
+
+	width := 0
+
+	for _, row := range rows {
+		if len(row.Cells) > width {
+			width = len(row.Cells)
+		}
+	}
+
+	names := make([][]string, width)
+
+	for _, row := range rows {
+
+		values := make([]string, width)
+
+		for i, cell := range row.Cells {
+
+			if cell.Value == "" {
+				continue
+			}
+
+			for j := i; j <= i+cell.HMerge && j < width; j++ {
+				values[j] = cell.Value
+			}
+		}
+
+		for i, val := range values {
+			if val != "" {
+				names[i] = append(names[i], val)
+			}
+		}
+	}
+
+	header := &x.Row{Sheet: sheet}
+
+	for _, parts := range names {
+		header.Cells = append(header.Cells, &x.Cell{Row: header, Value: strings.Join(parts, separator)})
+	}
+
+	return header
+}
+
+// dataRow get the zero based sheet row index of the i-th data row of sheet
+func (reader *Reader) dataRow(sheet string, i int) int {
+
+	if _, ok := reader.headers[sheet]; ok || reader.Headerless {
+		return reader.SkipRows + i
+	}
+
+	rows := 1
+
+	if reader.HeaderRows > 1 {
+		rows = reader.HeaderRows
+	}
+
+	return reader.HeaderRow + rows + reader.SkipRows + i
+}
+
+// isBlankRow check if all cells of row are empty or whitespace only
+func isBlankRow(row *x.Row) bool {
+	for _, cell := range row.Cells {
+		if strings.TrimSpace(cell.Value) != "" {
+			return false
+		}
+	}
+
+	return true
 }
@@ -0,0 +1,4184 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gsdocker/gslogger"
+	x "github.com/tealeg/xlsx"
+)
+
+// newTestReader create in memory xlsx reader with one sheet
+func newTestReader(t *testing.T, sheetName string, rows ...[]string) *Reader {
+	file := x.NewFile()
+
+	sheet, err := file.AddSheet(sheetName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range rows {
+		r := sheet.AddRow()
+
+		for _, val := range row {
+			r.AddCell().SetString(val)
+		}
+	}
+
+	return newReader(file)
+}
+
+type arrayRow struct {
+	Ints    [3]int
+	Strings [2]string
+}
+
+func TestReadArray(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Ints", "Strings"},
+		[]string{"1,2,3", "a,b"},
+		[]string{"4", ""},
+		[]string{"1,2,3,4", "a"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val arrayRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Ints != [3]int{1, 2, 3} || val.Strings != [2]string{"a", "b"} {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Ints != [3]int{4, 0, 0} || val.Strings != [2]string{"", ""} {
+		t.Fatalf("expect zero padded row: %v", val)
+	}
+
+	if err := rows[2].Read(&val); err == nil {
+		t.Fatal("expect array overflow error")
+	}
+}
+
+type timeRow struct {
+	Created time.Time
+	Day     time.Time `xlsx:"Day,layout:2006-01-02"`
+}
+
+func TestReadTime(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Created", "Day"},
+		[]string{"2017-03-04T05:06:07Z", "2017-03-04"},
+		[]string{"", ""},
+	)
+
+	expect := time.Date(2017, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	reader.file.Sheet["Sheet1"].AddRow().AddCell().SetDateTime(expect)
+
+	rows := reader.Read("Sheet1")
+
+	var val timeRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !val.Created.Equal(expect) || !val.Day.Equal(time.Date(2017, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !val.Created.IsZero() || !val.Day.IsZero() {
+		t.Fatalf("expect zero time: %v", val)
+	}
+
+	val = timeRow{}
+
+	if err := rows[2].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !val.Created.Equal(expect) {
+		t.Fatalf("unexpected excel date: %v", val.Created)
+	}
+}
+
+type countRow struct {
+	Count int
+}
+
+func TestReadErrorRowID(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+		[]string{"three"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	if rows[2].ID() != 2 {
+		t.Fatalf("unexpected row id %d", rows[2].ID())
+	}
+
+	var val countRow
+
+	err := rows[2].Read(&val)
+
+	if err == nil {
+		t.Fatal("expect conversion error")
+	}
+
+	if !strings.Contains(err.Error(), "cell[Sheet1.Count:2(A4)]") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+type multiRow struct {
+	A int
+	B float64
+	C string
+}
+
+func TestReadCollectErrors(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"A", "B", "C"},
+		[]string{"x", "y", "z"},
+	)
+
+	var val multiRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err == nil {
+		t.Fatal("expect conversion error")
+	} else if _, ok := err.(*MultiError); ok {
+		t.Fatal("expect first error only")
+	}
+
+	reader.CollectErrors = true
+
+	err := reader.Read("Sheet1")[0].Read(&val)
+
+	multi, ok := err.(*MultiError)
+
+	if !ok {
+		t.Fatalf("expect MultiError, got %v", err)
+	}
+
+	if len(multi.Errors()) != 2 {
+		t.Fatalf("expect 2 errors, got %d", len(multi.Errors()))
+	}
+
+	if val.C != "z" {
+		t.Fatalf("expect remaining cells read, got %v", val)
+	}
+}
+
+func TestNewReaderFromBinary(t *testing.T) {
+	source := newTestReader(t, "Sheet1",
+		[]string{"A", "B", "C"},
+		[]string{"1", "1.5", "a"},
+		[]string{"2", "2.5", "b"},
+	)
+
+	filename := filepath.Join(t.TempDir(), "test.xlsx")
+
+	if err := source.file.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromBinary, err := NewReaderFromBinary(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromReader, err := NewReaderFromReader(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(reader *Reader) (vals []multiRow) {
+		for _, row := range reader.Read("Sheet1") {
+			var val multiRow
+
+			if err := row.Read(&val); err != nil {
+				t.Fatal(err)
+			}
+
+			vals = append(vals, val)
+		}
+
+		return
+	}
+
+	expect := read(fromFile)
+
+	if len(expect) != 2 {
+		t.Fatalf("unexpected rows: %v", expect)
+	}
+
+	if !reflect.DeepEqual(expect, read(fromBinary)) || !reflect.DeepEqual(expect, read(fromReader)) {
+		t.Fatal("in memory reader result mismatch")
+	}
+}
+
+type scalarSliceRow struct {
+	Ints    []int
+	Floats  []float64
+	Strings []string
+	Bools   []bool
+}
+
+func TestReadScalarSlice(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Ints", "Floats", "Strings", "Bools"},
+		[]string{"1,2,3", "1.5,2", "a,b,c", "true,0,1"},
+		[]string{"", "", "", ""},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val scalarSliceRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := scalarSliceRow{
+		Ints:    []int{1, 2, 3},
+		Floats:  []float64{1.5, 2},
+		Strings: []string{"a", "b", "c"},
+		Bools:   []bool{true, false, true},
+	}
+
+	if !reflect.DeepEqual(val, expect) {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(val.Ints) != 0 || len(val.Floats) != 0 || len(val.Strings) != 0 || len(val.Bools) != 0 {
+		t.Fatalf("expect empty slices: %v", val)
+	}
+}
+
+type point struct {
+	X int
+	Y int
+}
+
+type structSliceRow struct {
+	Values   []point
+	Pointers []*point
+}
+
+func TestReadStructSlice(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Values", "Pointers"},
+		[]string{"1:2,3:4", "5:6"},
+	)
+
+	reader.Pattern = map[string]*regexp.Regexp{
+		"Sheet1.Values":   regexp.MustCompile(`(\d+):(\d+)`),
+		"Sheet1.Pointers": regexp.MustCompile(`(\d+):(\d+)`),
+	}
+
+	var val structSliceRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(val.Values, []point{{1, 2}, {3, 4}}) {
+		t.Fatalf("unexpected values: %v", val.Values)
+	}
+
+	if !reflect.DeepEqual(val.Pointers, []*point{{5, 6}}) {
+		t.Fatalf("unexpected pointers: %v", val.Pointers)
+	}
+}
+
+func TestReadHeaderRow(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Title banner"},
+		[]string{""},
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	reader.HeaderRow = 2
+
+	rows := reader.Read("Sheet1")
+
+	if len(rows) != 2 {
+		t.Fatalf("expect 2 rows, got %d", len(rows))
+	}
+
+	var val countRow
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Count != 2 {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	sheet := reader.file.Sheet["Sheet1"]
+
+	reader.HeaderRow = 5
+
+	if _, err := reader.readRows(context.Background(), sheet); err == nil {
+		t.Fatal("expect header row out of range error")
+	}
+}
+
+func TestReadSkipBlankRows(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{""},
+		[]string{"  "},
+		[]string{"2"},
+		[]string{},
+	)
+
+	if rows := reader.Read("Sheet1"); len(rows) != 5 {
+		t.Fatalf("expect 5 rows, got %d", len(rows))
+	}
+
+	reader.SkipBlankRows = true
+
+	rows := reader.Read("Sheet1")
+
+	if len(rows) != 2 {
+		t.Fatalf("expect 2 rows, got %d", len(rows))
+	}
+
+	if rows[1].ID() != 3 {
+		t.Fatalf("expect row id 3, got %d", rows[1].ID())
+	}
+}
+
+func TestReadDisallowUnknownColumns(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count", "Extra", "Other"},
+		[]string{"1", "x", "y"},
+	)
+
+	var val countRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	reader.DisallowUnknownColumns = true
+
+	err := reader.Read("Sheet1")[0].Read(&val)
+
+	unknown, ok := err.(*ErrUnknownColumns)
+
+	if !ok {
+		t.Fatalf("expect ErrUnknownColumns, got %v", err)
+	}
+
+	if !reflect.DeepEqual(unknown.Columns, []string{"Extra", "Other"}) {
+		t.Fatalf("unexpected unknown columns: %v", unknown.Columns)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"A", "B", "C"},
+		[]string{"1", "1.5", "a"},
+		[]string{"2", "2.5", "b"},
+	)
+
+	vals, err := ReadAll[multiRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(vals, []multiRow{{1, 1.5, "a"}, {2, 2.5, "b"}}) {
+		t.Fatalf("unexpected rows: %v", vals)
+	}
+
+	ptrs, err := ReadAll[*multiRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ptrs) != 2 || *ptrs[1] != vals[1] {
+		t.Fatalf("unexpected rows: %v", ptrs)
+	}
+
+	reader.file.Sheet["Sheet1"].Rows[1].Cells[0].Value = "x"
+
+	if _, err := ReadAll[multiRow](reader, "Sheet1"); err == nil {
+		t.Fatal("expect conversion error")
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{""},
+		[]string{"3"},
+	)
+
+	reader.SkipBlankRows = true
+
+	decoder := NewDecoder(reader, "Sheet1")
+
+	var counts []int
+
+	for decoder.More() {
+		var val countRow
+
+		if err := decoder.Decode(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		counts = append(counts, val.Count)
+	}
+
+	if !reflect.DeepEqual(counts, []int{1, 3}) {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+
+	if err := decoder.Decode(&countRow{}); err != io.EOF {
+		t.Fatalf("expect io.EOF, got %v", err)
+	}
+
+	decoder = NewDecoder(reader, "NotFound")
+
+	if !decoder.More() || decoder.Decode(&countRow{}) == nil {
+		t.Fatal("expect sheet not found error")
+	}
+
+	if decoder.More() {
+		t.Fatal("expect no more rows after error")
+	}
+}
+
+type color struct {
+	R, G, B uint8
+}
+
+type colorRow struct {
+	Fore    color
+	Back    color
+	Palette []color
+}
+
+func TestRegisterType(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Fore", "Back", "Palette"},
+		[]string{"#010203", "#040506", "#070809,#0a0b0c"},
+	)
+
+	reader.RegisterType(reflect.TypeOf(color{}), func(val reflect.Value, cell string) error {
+		var c color
+
+		if _, err := fmt.Sscanf(cell, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+			return err
+		}
+
+		val.Set(reflect.ValueOf(c))
+
+		return nil
+	})
+
+	reader.Unmarshalers = map[string]UnmarshalF{
+		"Sheet1.Back": func(val reflect.Value, cell string) error {
+			val.FieldByName("Back").Set(reflect.ValueOf(color{255, 255, 255}))
+			return nil
+		},
+	}
+
+	var val colorRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Fore != (color{1, 2, 3}) {
+		t.Fatalf("unexpected fore color: %v", val.Fore)
+	}
+
+	if val.Back != (color{255, 255, 255}) {
+		t.Fatalf("expect column unmarshaler precedence: %v", val.Back)
+	}
+
+	if !reflect.DeepEqual(val.Palette, []color{{7, 8, 9}, {10, 11, 12}}) {
+		t.Fatalf("unexpected palette: %v", val.Palette)
+	}
+}
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("invalid level %q", text)
+	}
+
+	return nil
+}
+
+type levelRow struct {
+	Level  level
+	Levels []level
+	IP     net.IP
+}
+
+func TestReadTextUnmarshaler(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Level", "Levels", "IP"},
+		[]string{"high", "low,high", "192.168.1.1"},
+		[]string{"medium", "", ""},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val levelRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Level != 2 || !reflect.DeepEqual(val.Levels, []level{1, 2}) || !val.IP.Equal(net.IPv4(192, 168, 1, 1)) {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	if err := rows[1].Read(&val); err == nil || !strings.Contains(err.Error(), "medium") {
+		t.Fatalf("expect invalid level error, got %v", err)
+	}
+}
+
+func TestReadWideRow(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1", "stray", "cells"},
+	)
+
+	var val countRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Count != 1 {
+		t.Fatalf("unexpected row: %v", val)
+	}
+}
+
+type userRow struct {
+	UserID int
+}
+
+func TestReadCaseInsensitive(t *testing.T) {
+	for _, header := range []string{"User ID", "user_id", "USERID"} {
+		reader := newTestReader(t, "Sheet1",
+			[]string{header},
+			[]string{"7"},
+		)
+
+		var val userRow
+
+		if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		if val.UserID != 0 {
+			t.Fatalf("expect exact matching by default: %s", header)
+		}
+
+		reader.CaseInsensitive = true
+
+		if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		if val.UserID != 7 {
+			t.Fatalf("can't match %s to UserID", header)
+		}
+	}
+}
+
+type weightRow struct {
+	Weight float64
+	Height float64 `xlsx:"height"`
+	Age    int
+}
+
+func TestReadNameFunc(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Weight (kg)", "Height (cm)", "Years (y)"},
+		[]string{"70.5", "180", "30"},
+	)
+
+	reader.NameFunc = func(header string) string {
+		if i := strings.Index(header, " ("); i != -1 {
+			header = header[:i]
+		}
+
+		return strings.ToLower(header)
+	}
+
+	reader.NameMapping = map[string]string{
+		"Sheet1.years":  "Age",
+		"Sheet1.weight": "Weight",
+	}
+
+	var val weightRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (weightRow{70.5, 180, 30}) {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	reader.NameFunc = nil
+
+	val = weightRow{}
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (weightRow{}) {
+		t.Fatalf("expect no matching without NameFunc: %v", val)
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	file := x.NewFile()
+
+	sheet, _ := file.AddSheet("Sheet1")
+
+	header := sheet.AddRow()
+
+	for _, name := range []string{"A", "B", "C", "Ints", "Floats"} {
+		header.AddCell().SetString(name)
+	}
+
+	for i := 0; i < 1000; i++ {
+		row := sheet.AddRow()
+
+		for _, val := range []string{"1", "1.5", "a", "1,2,3", "1.5,2"} {
+			row.AddCell().SetString(val)
+		}
+	}
+
+	reader := &Reader{Log: gslogger.Get("xlsx"), file: file}
+
+	type benchRow struct {
+		A      int
+		B      float64
+		C      string
+		Ints   []int
+		Floats []float64
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, row := range reader.Read("Sheet1") {
+			var val benchRow
+
+			if err := row.Read(&val); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+type optionalRow struct {
+	Int    *int
+	Float  *float64
+	String *string
+}
+
+func TestReadOptional(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Int", "Float", "String"},
+		[]string{"1", "1.5", "a"},
+		[]string{"", "", ""},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val optionalRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Int == nil || *val.Int != 1 || val.Float == nil || *val.Float != 1.5 || val.String == nil || *val.String != "a" {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	val = optionalRow{}
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Int != nil || val.Float != nil || val.String != nil {
+		t.Fatalf("expect nil pointers: %v", val)
+	}
+}
+
+func TestSheetNames(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+	)
+
+	if _, err := reader.file.AddSheet("Empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reader.SheetNames(), []string{"Sheet1", "Empty"}) {
+		t.Fatalf("unexpected sheet names: %v", reader.SheetNames())
+	}
+
+	if !reader.HasSheet("Empty") || reader.HasSheet("Sheet2") {
+		t.Fatal("HasSheet mismatch")
+	}
+
+	if rows, err := reader.ReadSheet("Empty"); err != nil || rows != nil {
+		t.Fatalf("expect empty sheet without error, got %v %v", rows, err)
+	}
+
+	if _, err := reader.ReadSheet("Sheet2"); err == nil {
+		t.Fatal("expect sheet not found error")
+	} else if _, ok := err.(*ErrSheetNotFound); !ok {
+		t.Fatalf("expect ErrSheetNotFound, got %v", err)
+	}
+}
+
+func TestReadIndex(t *testing.T) {
+	reader := newTestReader(t, "Unpredictable",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	rows := reader.ReadIndex(0)
+
+	if len(rows) != 2 {
+		t.Fatalf("expect 2 rows, got %d", len(rows))
+	}
+
+	var val countRow
+
+	if err := rows[1].Read(&val); err != nil || val.Count != 2 {
+		t.Fatalf("unexpected row: %v %v", val, err)
+	}
+
+	if reader.ReadIndex(1) != nil || reader.ReadIndex(-1) != nil {
+		t.Fatal("expect nil for out of range index")
+	}
+}
+
+type splitRow struct {
+	Tags   []string `xlsx:"Tags,split:;"`
+	Ints   []int
+	Points [2]int
+}
+
+func TestReadSplit(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Tags", "Ints", "Points"},
+		[]string{"a,b;c", "1|2|3", "4,5"},
+	)
+
+	reader.Splits = map[string]string{
+		"Sheet1.Ints": "|",
+	}
+
+	var val splitRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := splitRow{
+		Tags:   []string{"a,b", "c"},
+		Ints:   []int{1, 2, 3},
+		Points: [2]int{4, 5},
+	}
+
+	if !reflect.DeepEqual(val, expect) {
+		t.Fatalf("unexpected row: %v", val)
+	}
+}
+
+type namedPoint struct {
+	Label string `xlsx:"L"`
+	X     int
+	Y     int
+}
+
+func TestReadNamedSubexp(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Points"},
+		[]string{"1:2@a,3:4@b"},
+	)
+
+	reader.Pattern = map[string]*regexp.Regexp{
+		"Sheet1.Points": regexp.MustCompile(`(?P<X>\d+):(?P<Y>\d+)@(?P<L>\w+)`),
+	}
+
+	var val struct {
+		Points []namedPoint
+	}
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(val.Points, []namedPoint{{"a", 1, 2}, {"b", 3, 4}}) {
+		t.Fatalf("unexpected points: %v", val.Points)
+	}
+}
+
+type blankRow struct {
+	Int   int
+	Float float64
+	Bool  bool
+}
+
+func TestReadEmptyAsZero(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Int", "Float", "Bool"},
+		[]string{"", " ", ""},
+	)
+
+	val := blankRow{1, 1.5, true}
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (blankRow{}) {
+		t.Fatalf("expect zero values: %v", val)
+	}
+
+	reader.EmptyAsZero = false
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err == nil {
+		t.Fatal("expect empty numeric cell error")
+	}
+
+	var flag struct {
+		Bool bool
+	}
+
+	if err := reader.Read("Sheet1")[0].Read(&flag); err == nil || !strings.Contains(err.Error(), "to bool") {
+		t.Fatalf("expect empty bool cell error, got %v", err)
+	}
+}
+
+type paddedRow struct {
+	Int    int
+	Floats []float64
+	Name   string
+	Tags   []string
+	Nick   *string
+	Note   sql.NullString
+	Count  *int
+}
+
+func TestReadTrimSpace(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Int", "Floats", "Name", "Tags", "Nick", "Note", "Count"},
+		[]string{" 42 ", " 1.5 , 2 ", " a ", " b , c ", "  y ", " z ", " 7 "},
+	)
+
+	var val paddedRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err == nil {
+		t.Fatal("expect padded number error")
+	}
+
+	reader.TrimSpace = true
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	nick, count := "  y ", 7
+
+	// the strings behind pointers and nullable structs are only trimmed by TrimStrings too
+	expect := paddedRow{42, []float64{1.5, 2}, " a ", []string{" b ", " c "}, &nick, sql.NullString{String: " z ", Valid: true}, &count}
+
+	if !reflect.DeepEqual(val, expect) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	reader.TrimStrings = true
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Name != "a" || !reflect.DeepEqual(val.Tags, []string{"b", "c"}) || *val.Nick != "y" || val.Note.String != "z" {
+		t.Fatalf("expect trimmed strings: %#v", val)
+	}
+}
+
+type nullRow struct {
+	String sql.NullString
+	Int64  sql.NullInt64
+	Float  sql.NullFloat64
+	Bool   sql.NullBool
+}
+
+func TestReadSQLNull(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"String", "Int64", "Float", "Bool"},
+		[]string{"a", "1", "1.5", "true"},
+		[]string{"", "", "", ""},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val nullRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := nullRow{
+		String: sql.NullString{String: "a", Valid: true},
+		Int64:  sql.NullInt64{Int64: 1, Valid: true},
+		Float:  sql.NullFloat64{Float64: 1.5, Valid: true},
+		Bool:   sql.NullBool{Bool: true, Valid: true},
+	}
+
+	if val != expect {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (nullRow{}) {
+		t.Fatalf("expect invalid values: %v", val)
+	}
+}
+
+func TestReadErrorCellRef(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Title"},
+		[]string{"A", "B", "C"},
+		[]string{"1", "1.5", "a"},
+		[]string{"2", "x", "b"},
+	)
+
+	reader.HeaderRow = 1
+
+	var val multiRow
+
+	err := reader.Read("Sheet1")[1].Read(&val)
+
+	if err == nil || !strings.Contains(err.Error(), "(B4)") {
+		t.Fatalf("expect B4 cell reference, got %v", err)
+	}
+}
+
+type skipRow struct {
+	Name   string
+	Secret string `xlsx:"-"`
+}
+
+func TestReadSkipField(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Secret", "Hidden"},
+		[]string{"a", "b", "c"},
+	)
+
+	reader.DisallowUnknownColumns = true
+
+	// the mapped name of field tagged with "-" is skipped too
+	reader.NameMapping = map[string]string{
+		"Sheet1.Hidden": "Secret",
+	}
+
+	var val skipRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (skipRow{Name: "a"}) {
+		t.Fatalf("expect skipped field, got %v", val)
+	}
+}
+
+type precedenceRow struct {
+	Name string `xlsx:"Full Name"`
+	Nick string
+}
+
+func TestReadTagOverNameMapping(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Full Name"},
+		[]string{"a"},
+	)
+
+	reader.NameMapping = map[string]string{
+		"Sheet1.Full Name": "Nick",
+	}
+
+	var val precedenceRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (precedenceRow{Name: "a"}) {
+		t.Fatalf("expect tag wins over name mapping, got %v", val)
+	}
+}
+
+func TestReadMap(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Age", "Score", "Active", "Name", "Old"},
+		[]string{"a", "30", "1.5", "TRUE", "b"},
+	)
+
+	reader.NameMapping = map[string]string{
+		"Sheet1.Old": "Renamed",
+	}
+
+	row := reader.Read("Sheet1")[0]
+
+	expect := map[string]string{
+		"Name":    "a",
+		"Age":     "30",
+		"Score":   "1.5",
+		"Active":  "TRUE",
+		"Renamed": "",
+	}
+
+	if vals := row.ReadMap(); !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected map: %v", vals)
+	}
+
+	expectInterface := map[string]interface{}{
+		"Name":    "a",
+		"Age":     int64(30),
+		"Score":   1.5,
+		"Active":  true,
+		"Renamed": "",
+	}
+
+	if vals := row.ReadMapInterface(); !reflect.DeepEqual(vals, expectInterface) {
+		t.Fatalf("unexpected map: %v", vals)
+	}
+}
+
+func TestReadMapInterfaceNonFinite(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"A", "B", "C", "D"},
+		[]string{"nan", "inf", "-Infinity", "1e3"},
+	)
+
+	expect := map[string]interface{}{
+		"A": "nan",
+		"B": "inf",
+		"C": "-Infinity",
+		"D": 1000.0,
+	}
+
+	if vals := reader.Read("Sheet1")[0].ReadMapInterface(); !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("expect non-finite texts kept as strings, got %v", vals)
+	}
+}
+
+type typedRow struct {
+	Amount float64
+	Count  int
+	Active bool
+	Day    time.Time
+}
+
+func TestReadTypedCells(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Amount", "Count", "Active", "Day"},
+	)
+
+	row := reader.file.Sheet["Sheet1"].AddRow()
+
+	row.AddCell().SetFloatWithFormat(1234.5, "#,##0.00")
+	row.AddCell().SetFloatWithFormat(1e3, "0.00E+00")
+	row.AddCell().SetBool(true)
+	row.AddCell().SetDate(time.Date(2017, 3, 4, 0, 0, 0, 0, time.UTC))
+
+	// numeric cell stored in scientific notation can't be parsed by strconv.ParseInt
+	row.Cells[1].Value = "1E+3"
+
+	var val typedRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := typedRow{1234.5, 1000, true, time.Date(2017, 3, 4, 0, 0, 0, 0, time.UTC)}
+
+	if val != expect {
+		t.Fatalf("unexpected row: %v", val)
+	}
+}
+
+type categoryRow struct {
+	Category string
+	Item     string
+}
+
+func TestReadFillDown(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Category", "Item"},
+		[]string{"Fruit", "Apple"},
+		[]string{"", "Pear"},
+		[]string{"Veg", "Leek"},
+		[]string{"", ""},
+	)
+
+	reader.FillDown = []string{"Category"}
+
+	vals, err := ReadAll[categoryRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []categoryRow{{"Fruit", "Apple"}, {"Fruit", "Pear"}, {"Veg", "Leek"}, {"Veg", ""}}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %v", vals)
+	}
+
+	if reader.file.Sheet["Sheet1"].Rows[2].Cells[0].Value != "" {
+		t.Fatal("expect sheet rows untouched")
+	}
+
+	reader.FillDown = nil
+	reader.FillDownAll = true
+
+	decoder := NewDecoder(reader, "Sheet1")
+
+	var last categoryRow
+
+	for decoder.More() {
+		if err := decoder.Decode(&last); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if last != (categoryRow{"Veg", "Leek"}) {
+		t.Fatalf("unexpected last row: %v", last)
+	}
+}
+
+type address struct {
+	City string
+	Zip  string `xlsx:"Post Code"`
+	Geo  *geo
+}
+
+type geo struct {
+	Lat float64
+	Lng float64
+}
+
+type personRow struct {
+	Name    string
+	Address address
+	Office  *address
+}
+
+func TestReadNested(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Address.City", "Address.Post Code", "Address.Geo.Lat", "Office.City", "Office.Geo.Lng"},
+		[]string{"a", "Paris", "75001", "48.8", "Lyon", "4.8"},
+	)
+
+	var val personRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Address.City != "Paris" || val.Address.Zip != "75001" || val.Address.Geo == nil || val.Address.Geo.Lat != 48.8 {
+		t.Fatalf("unexpected address: %v", val.Address)
+	}
+
+	if val.Office == nil || val.Office.City != "Lyon" || val.Office.Geo == nil || val.Office.Geo.Lng != 4.8 {
+		t.Fatalf("unexpected office: %v", val.Office)
+	}
+}
+
+type Base struct {
+	ID   int
+	Kind string `xlsx:"Type"`
+}
+
+type Meta struct {
+	Owner string
+}
+
+type embeddedRow struct {
+	Base
+	*Meta
+	Extra string
+}
+
+func TestReadEmbedded(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"ID", "Type", "owner", "Extra"},
+		[]string{"1", "a", "b", "c"},
+	)
+
+	reader.CaseInsensitive = true
+
+	var val embeddedRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.ID != 1 || val.Kind != "a" || val.Meta == nil || val.Owner != "b" || val.Extra != "c" {
+		t.Fatalf("unexpected row: %v", val)
+	}
+}
+
+type base struct {
+	ID int
+}
+
+func TestReadEmbeddedUnexportedPointer(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"ID", "Extra"},
+		[]string{"1", "a"},
+	)
+
+	var val struct {
+		*base
+		Extra string
+	}
+
+	err := reader.Read("Sheet1")[0].Read(&val)
+
+	if e, ok := err.(*ErrUnmarshalField); !ok || e.Key != "ID" || e.Field.Name != "base" {
+		t.Fatalf("expect unexported embedded pointer error, got %v", err)
+	}
+
+	// the embedded value of unexported struct is settable
+	var embedded struct {
+		base
+		Extra string
+	}
+
+	if err := reader.Read("Sheet1")[0].Read(&embedded); err != nil || embedded.ID != 1 || embedded.Extra != "a" {
+		t.Fatalf("unexpected result: %v %v", embedded, err)
+	}
+}
+
+func TestReadContext(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	if rows, err := reader.ReadContext(context.Background(), "Sheet1"); err != nil || len(rows) != 2 {
+		t.Fatalf("unexpected result: %v %v", rows, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	decoder := NewDecoder(reader, "Sheet1")
+
+	var val countRow
+
+	if err := decoder.DecodeContext(ctx, &val); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+
+	if err := decoder.DecodeContext(ctx, &val); err != context.Canceled {
+		t.Fatalf("expect context.Canceled, got %v", err)
+	}
+
+	if _, err := reader.ReadContext(ctx, "Sheet1"); err != context.Canceled {
+		t.Fatalf("expect context.Canceled, got %v", err)
+	}
+}
+
+type switchRow struct {
+	Enabled bool
+	Power   bool
+}
+
+func TestReadBoolValues(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Enabled", "Power"},
+		[]string{"Yes", "on"},
+		[]string{"NO", "Off"},
+		[]string{"maybe", "off"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val switchRow
+
+	if err := rows[0].Read(&val); err != nil || val.Enabled || val.Power {
+		t.Fatalf("expect unknown values as false: %#v %v", val, err)
+	}
+
+	reader.TrueValues = []string{"yes", "on"}
+	reader.FalseValues = []string{"no", "off"}
+
+	rows = reader.Read("Sheet1")
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !val.Enabled || !val.Power {
+		t.Fatalf("expect true values: %#v", val)
+	}
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Enabled || val.Power {
+		t.Fatalf("expect false values: %#v", val)
+	}
+
+	err := rows[2].Read(&val)
+
+	if err == nil || !strings.Contains(err.Error(), "'maybe' to bool") {
+		t.Fatalf("expect unknown bool value error, got %v", err)
+	}
+}
+
+type amountRow struct {
+	Count  int
+	Amount float64
+	Rate   float64
+}
+
+func TestReadFormattedNumbers(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count", "Amount", "Rate"},
+		[]string{"1,000", "1,234.56", "1.5%"},
+	)
+
+	var val amountRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err == nil {
+		t.Fatal("expect formatted number error")
+	}
+
+	reader.FormattedNumbers = true
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (amountRow{1000, 1234.56, 0.015}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	reader = newTestReader(t, "Sheet1",
+		[]string{"Count", "Amount", "Rate"},
+		[]string{"2.000", "1.234,56", "12,5%"},
+	)
+
+	reader.FormattedNumbers = true
+	reader.DecimalSeparator = ','
+	reader.ThousandsSeparator = '.'
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (amountRow{2000, 1234.56, 0.125}) {
+		t.Fatalf("unexpected european row: %#v", val)
+	}
+
+	// the stored numbers of numeric cells are not normalized by the separators
+	row := reader.file.Sheet["Sheet1"].AddRow()
+
+	row.AddCell().SetInt(2000)
+	row.AddCell().SetFloatWithFormat(1234.56, "#,##0.00")
+	row.AddCell().SetFloat(0.5)
+
+	if err := reader.Read("Sheet1")[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (amountRow{2000, 1234.56, 0.5}) {
+		t.Fatalf("unexpected numeric row: %#v", val)
+	}
+}
+
+type sizedRow struct {
+	Small int8
+	Port  uint16
+}
+
+func TestReadIntOverflow(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Small", "Port"},
+		[]string{"-128", "65535"},
+		[]string{"128", "1"},
+		[]string{"1", "65536"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val sizedRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (sizedRow{-128, 65535}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	if err := rows[1].Read(&val); err == nil || !strings.Contains(err.Error(), "cell[Sheet1.Small:1(A3)] '128' to int8") {
+		t.Fatalf("expect int8 overflow error, got %v", err)
+	}
+
+	if err := rows[2].Read(&val); err == nil || !strings.Contains(err.Error(), "cell[Sheet1.Port:2(B4)] '65536' to uint16") {
+		t.Fatalf("expect uint16 overflow error, got %v", err)
+	}
+}
+
+func TestReadIntBase(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Small", "Port"},
+		[]string{"010", "09"},
+		[]string{"-0x10", "0xFF"},
+		[]string{"0o7", "0b1"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val sizedRow
+
+	// the leading zeros are decimal, not octal
+	if err := rows[0].Read(&val); err != nil || val != (sizedRow{10, 9}) {
+		t.Fatalf("unexpected row: %#v %v", val, err)
+	}
+
+	if err := rows[1].Read(&val); err != nil || val != (sizedRow{-16, 255}) {
+		t.Fatalf("unexpected hex row: %#v %v", val, err)
+	}
+
+	if err := rows[2].Read(&val); err == nil {
+		t.Fatalf("expect octal and binary prefixes rejected, got %#v", val)
+	}
+}
+
+type stockRow struct {
+	Name  string
+	Price float64
+	Qty   uint
+}
+
+func TestReadNegativeUint(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Price", "Qty"},
+		[]string{"apple", "1.5", "3"},
+		[]string{"pear", "2", "-5"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val stockRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	err := rows[1].Read(&val)
+
+	if err == nil || !strings.Contains(err.Error(), "cell[Sheet1.Qty:1(C3)]: negative value -5 into unsigned field Qty") || strings.Count(err.Error(), "C3") != 1 {
+		t.Fatalf("expect negative value error, got %v", err)
+	}
+}
+
+func TestReadRange(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"0"},
+		[]string{"1"},
+		[]string{"2"},
+		[]string{"3"},
+		[]string{"4"},
+	)
+
+	rows := reader.ReadRange("Sheet1", 1, 3)
+
+	if len(rows) != 2 {
+		t.Fatalf("expect 2 rows, got %d", len(rows))
+	}
+
+	for i, row := range rows {
+		var val countRow
+
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		if val.Count != i+1 || row.ID() != i+1 {
+			t.Fatalf("unexpected row %d: %#v", row.ID(), val)
+		}
+	}
+
+	if rows := reader.ReadRange("Sheet1", 3, 0); len(rows) != 2 {
+		t.Fatalf("expect rows to the end, got %d", len(rows))
+	}
+
+	if rows := reader.ReadRange("Sheet1", 3, 100); len(rows) != 2 {
+		t.Fatalf("expect clipped range, got %d", len(rows))
+	}
+
+	if rows := reader.ReadRange("Sheet1", 10, 20); rows != nil {
+		t.Fatalf("expect nil for out of bounds range, got %d rows", len(rows))
+	}
+}
+
+type statusRow struct {
+	Name   string
+	Status string
+}
+
+func TestReadFilter(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Status"},
+		[]string{"a", "Y"},
+		[]string{"b", "N"},
+		[]string{"c", "Y"},
+		[]string{"d"},
+	)
+
+	rows := reader.ReadFilter("Sheet1", func(row *RowReader) bool {
+		status, _ := row.Cell("Status")
+		return status == "Y"
+	})
+
+	if len(rows) != 2 {
+		t.Fatalf("expect 2 active rows, got %d", len(rows))
+	}
+
+	var names []string
+
+	for _, row := range rows {
+		var val statusRow
+
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		names = append(names, val.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"a", "c"}) {
+		t.Fatalf("unexpected rows: %v", names)
+	}
+
+	if _, ok := rows[0].Cell("Missing"); ok {
+		t.Fatal("expect missing column")
+	}
+}
+
+func TestRowReaderCell(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Old", "Note"},
+		[]string{"a", "b"},
+	)
+
+	reader.NameMapping = map[string]string{
+		"Sheet1.Old": "Renamed",
+	}
+
+	row := reader.Read("Sheet1")[0]
+
+	if cols := row.Columns(); !reflect.DeepEqual(cols, []string{"Name", "Renamed", "Note"}) {
+		t.Fatalf("unexpected columns: %v", cols)
+	}
+
+	if val, ok := row.Cell("Name"); !ok || val != "a" {
+		t.Fatalf("unexpected Name cell: %q %v", val, ok)
+	}
+
+	if val, ok := row.Cell("Renamed"); !ok || val != "b" {
+		t.Fatalf("unexpected renamed cell: %q %v", val, ok)
+	}
+
+	if val, ok := row.Cell("Note"); !ok || val != "" {
+		t.Fatalf("expect empty cell of short row: %q %v", val, ok)
+	}
+
+	if _, ok := row.Cell("Missing"); ok {
+		t.Fatal("expect absent column")
+	}
+}
+
+type durationRow struct {
+	Timeout time.Duration
+	Retry   time.Duration
+	Raw     time.Duration
+}
+
+func TestReadDuration(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Timeout", "Retry", "Raw"},
+		[]string{"1h30m", "2s", "1000000000"},
+	)
+
+	var val durationRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err == nil || !strings.Contains(err.Error(), "'1000000000' to duration") {
+		t.Fatalf("expect bare number duration error, got %v", err)
+	}
+
+	reader.DurationNanoseconds = true
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (durationRow{90 * time.Minute, 2 * time.Second, time.Second}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+}
+
+func TestReaderClose(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	if rows := reader.Read("Sheet1"); len(rows) != 2 {
+		t.Fatalf("expect 2 rows, got %d", len(rows))
+	}
+
+	decoder := NewDecoder(reader, "Sheet1")
+
+	var val countRow
+
+	if err := decoder.Decode(&val); err != nil || val.Count != 1 {
+		t.Fatalf("unexpected decode: %v %v", val, err)
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the decoder created before close stops at the next row
+	if err := decoder.Decode(&val); err != ErrClosed {
+		t.Fatalf("expect ErrClosed, got %v", err)
+	}
+
+	if rows := reader.Read("Sheet1"); rows != nil {
+		t.Fatalf("expect no rows after close, got %d", len(rows))
+	}
+
+	if _, err := reader.ReadSheet("Sheet1"); err != ErrClosed {
+		t.Fatalf("expect ErrClosed, got %v", err)
+	}
+
+	if _, err := ReadAll[countRow](reader, "Sheet1"); err != ErrClosed {
+		t.Fatalf("expect ErrClosed, got %v", err)
+	}
+
+	if err := reader.Validate("Sheet1", countRow{}); err != ErrClosed {
+		t.Fatalf("expect ErrClosed, got %v", err)
+	}
+
+	if err := reader.Stream(context.Background(), "Sheet1", make(chan *RowReader)); err != ErrClosed {
+		t.Fatalf("expect ErrClosed, got %v", err)
+	}
+
+	if reader.HasSheet("Sheet1") || reader.SheetNames() != nil || reader.RowCount("Sheet1") != -1 || reader.ReadIndex(0) != nil || reader.Sheet("Sheet1") != nil {
+		t.Fatal("expect closed reader to have no sheets")
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatalf("expect closing twice to succeed, got %v", err)
+	}
+}
+
+func TestNewReaderClose(t *testing.T) {
+	source := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+	)
+
+	filename := filepath.Join(t.TempDir(), "close.xlsx")
+
+	if err := source.file.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		reader, err := NewReader(filename)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if vals, err := ReadAll[countRow](reader, "Sheet1"); err != nil || len(vals) != 1 || vals[0].Count != 1 {
+			t.Fatalf("unexpected result: %v %v", vals, err)
+		}
+
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := reader.ReadSheet("Sheet1"); err != ErrClosed {
+			t.Fatalf("expect ErrClosed, got %v", err)
+		}
+	}
+}
+
+type bigRow struct {
+	Total   *big.Int
+	Balance *big.Float
+	Count   big.Int
+}
+
+func TestReadBig(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Total", "Balance", "Count"},
+		[]string{"1234567890123456789012345678901234567890", "3.14159265358979323846264338327950288", "42"},
+		[]string{"", "", ""},
+		[]string{"12a", "1", "1"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val bigRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Total == nil || val.Total.String() != "1234567890123456789012345678901234567890" {
+		t.Fatalf("unexpected Total: %v", val.Total)
+	}
+
+	if val.Balance == nil || val.Balance.Text('f', 35) != "3.14159265358979323846264338327950288" {
+		t.Fatalf("unexpected Balance: %v", val.Balance)
+	}
+
+	if val.Count.Int64() != 42 {
+		t.Fatalf("unexpected Count: %v", &val.Count)
+	}
+
+	var empty bigRow
+
+	if err := rows[1].Read(&empty); err != nil {
+		t.Fatal(err)
+	}
+
+	if empty.Total != nil || empty.Balance != nil || empty.Count.Sign() != 0 {
+		t.Fatalf("expect empty values: %#v", empty)
+	}
+
+	if err := rows[2].Read(&val); err == nil || !strings.Contains(err.Error(), "'12a' to big.Int") {
+		t.Fatalf("expect big.Int error, got %v", err)
+	}
+}
+
+type indexRow struct {
+	Name  string  `xlsx:"col:0"`
+	Score float64 `xlsx:"col:2"`
+	Age   int     `xlsx:"col:1"`
+	Note  string
+}
+
+func TestReadIndexTagWithHeader(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Age", "Score"},
+		[]string{"a", "30", "1.5"},
+	)
+
+	reader.DisallowUnknownColumns = true
+
+	var val indexRow
+
+	// the col tag isn't the column name, the headered sheet matches fields by name
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil || val != (indexRow{"a", 1.5, 30, ""}) {
+		t.Fatalf("unexpected row: %#v %v", val, err)
+	}
+}
+
+func TestReadHeaderless(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"a", "30", "1.5"},
+		[]string{"b", "40", "2.5"},
+		[]string{"c", "x", "3.5"},
+	)
+
+	reader.Headerless = true
+
+	rows := reader.Read("Sheet1")
+
+	if len(rows) != 3 {
+		t.Fatalf("expect 3 rows, got %d", len(rows))
+	}
+
+	var vals []indexRow
+
+	for _, row := range rows[:2] {
+		var val indexRow
+
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		vals = append(vals, val)
+	}
+
+	expect := []indexRow{{"a", 1.5, 30, ""}, {"b", 2.5, 40, ""}}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %#v", vals)
+	}
+
+	var val indexRow
+
+	if err := rows[2].Read(&val); err == nil || !strings.Contains(err.Error(), "cell[Sheet1.Age:2(B3)]") {
+		t.Fatalf("expect cell error of headerless row, got %v", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	rows := [][]string{{"Count"}}
+
+	for i := 0; i < 50; i++ {
+		rows = append(rows, []string{fmt.Sprint(i)})
+	}
+
+	reader := newTestReader(t, "Sheet1", rows...)
+
+	out := make(chan *RowReader)
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- reader.Stream(context.Background(), "Sheet1", out)
+	}()
+
+	count := 0
+
+	for row := range out {
+		var val countRow
+
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		if val.Count != count {
+			t.Fatalf("unexpected row %d: %#v", count, val)
+		}
+
+		count++
+	}
+
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	if count != len(reader.Read("Sheet1")) {
+		t.Fatalf("expect %d rows, got %d", len(reader.Read("Sheet1")), count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	out = make(chan *RowReader)
+
+	go func() {
+		errc <- reader.Stream(ctx, "Sheet1", out)
+	}()
+
+	<-out
+
+	cancel()
+
+	for range out {
+	}
+
+	if err := <-errc; err != context.Canceled {
+		t.Fatalf("expect context canceled, got %v", err)
+	}
+}
+
+type nameRow struct {
+	Name string
+	Age  int
+}
+
+func TestReadDuplicateColumns(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Age", "Name"},
+		[]string{"first", "30", "second"},
+	)
+
+	var val nameRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (nameRow{"first", 30}) {
+		t.Fatalf("expect the first column wins: %#v", val)
+	}
+
+	reader.DisallowDuplicateColumns = true
+
+	err := reader.Read("Sheet1")[0].Read(&val)
+
+	if e, ok := err.(*ErrDuplicateColumns); !ok || !reflect.DeepEqual(e.Columns, []string{"Name"}) {
+		t.Fatalf("expect ErrDuplicateColumns, got %v", err)
+	}
+}
+
+type sumRow struct {
+	A   int
+	B   int
+	Sum string
+}
+
+func TestReadFormula(t *testing.T) {
+	file := x.NewFile()
+
+	sheet, err := file.AddSheet("Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := sheet.AddRow()
+
+	for _, name := range []string{"A", "B", "Sum"} {
+		header.AddCell().SetString(name)
+	}
+
+	row := sheet.AddRow()
+	row.AddCell().SetInt(1)
+	row.AddCell().SetInt(2)
+
+	sum := row.AddCell()
+	sum.SetFormula("A2+B2")
+	sum.Value = "3"
+
+	var buf bytes.Buffer
+
+	if err := file.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReaderFromBinary(buf.Bytes())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var val sumRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (sumRow{1, 2, "3"}) {
+		t.Fatalf("expect cached formula result: %#v", val)
+	}
+
+	reader.PreferCachedValue = false
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Sum != "=A2+B2" {
+		t.Fatalf("expect formula text: %#v", val)
+	}
+}
+
+type defaultRow struct {
+	Name    string      `xlsx:"Name,default:N/A"`
+	Retries int         `xlsx:"Retries,default:3"`
+	Enabled bool        `xlsx:"Enabled,default:true"`
+	Start   time.Time   `xlsx:"Start,default:2020-01-01T00:00:00Z"`
+	Extra   interface{} `xlsx:"Extra,default:none"`
+	Note    string
+}
+
+func TestReadDefault(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Note", "Name", "Retries", "Enabled", "Start", "Extra"},
+		[]string{"a", "", "", "", "", ""},
+		[]string{"b", "x", "5", "false", "2021-02-03T00:00:00Z", "y"},
+		[]string{"c"},
+	)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var vals []defaultRow
+
+	for _, row := range reader.Read("Sheet1") {
+		var val defaultRow
+
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		vals = append(vals, val)
+	}
+
+	expect := []defaultRow{
+		{"N/A", 3, true, start, "none", "a"},
+		{"x", 5, false, time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC), "y", "b"},
+		{"N/A", 3, true, start, "none", "c"},
+	}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %#v", vals)
+	}
+}
+
+type validRow struct {
+	Name  string  `xlsx:"Name,required"`
+	Age   int     `xlsx:"Age,min:0,max:120"`
+	Score float64 `xlsx:"Score,max:1.5"`
+	Note  string
+}
+
+func TestReadValidation(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Age", "Score", "Note"},
+		[]string{"a", "30", "1.5", ""},
+		[]string{" ", "30", "1"},
+		[]string{"b", "-1", "1"},
+		[]string{"c", "121", "1"},
+		[]string{"d", "0", "1.6"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val validRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expects := []string{
+		"cell[Sheet1.Name:1(A3)] is required",
+		"cell[Sheet1.Age:2(B4)] value -1 is less than min 0",
+		"cell[Sheet1.Age:3(B5)] value 121 is greater than max 120",
+		"cell[Sheet1.Score:4(C6)] value 1.6 is greater than max 1.5",
+	}
+
+	for i, expect := range expects {
+		if err := rows[i+1].Read(&val); err == nil || !strings.Contains(err.Error(), expect) {
+			t.Fatalf("expect error %q, got %v", expect, err)
+		}
+	}
+}
+
+func TestReadRequiredMissingColumn(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Age", "Score", "Note"},
+		[]string{"30", "1.5", ""},
+	)
+
+	var val validRow
+
+	err := reader.Read("Sheet1")[0].Read(&val)
+
+	if e, ok := err.(*ErrMissingFields); !ok || !reflect.DeepEqual(e.Fields, []string{"Name"}) {
+		t.Fatalf("expect missing required field Name, got %v", err)
+	}
+
+	// the RequireAllFields error already names the required field
+	reader.RequireAllFields = true
+
+	err = reader.Read("Sheet1")[0].Read(&val)
+
+	if e, ok := err.(*ErrMissingFields); !ok || !reflect.DeepEqual(e.Fields, []string{"Name"}) {
+		t.Fatalf("expect missing field Name, got %v", err)
+	}
+}
+
+type mapRow struct {
+	Labels map[string]string
+	Counts map[string]int
+	Names  map[int]string
+}
+
+func TestReadMapField(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Labels", "Counts", "Names"},
+		[]string{"env:prod,team:core", "a:1,b:2", "1:one,2:two"},
+		[]string{"", "", ""},
+		[]string{"env", "a:x", ""},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val mapRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := mapRow{
+		Labels: map[string]string{"env": "prod", "team": "core"},
+		Counts: map[string]int{"a": 1, "b": 2},
+		Names:  map[int]string{1: "one", 2: "two"},
+	}
+
+	if !reflect.DeepEqual(val, expect) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	var empty mapRow
+
+	if err := rows[1].Read(&empty); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(empty.Labels) != 0 || len(empty.Counts) != 0 || len(empty.Names) != 0 {
+		t.Fatalf("expect empty maps: %#v", empty)
+	}
+
+	reader.CollectErrors = true
+
+	err := reader.Read("Sheet1")[2].Read(&val)
+
+	if e, ok := err.(*MultiError); !ok || len(e.Errors()) != 2 {
+		t.Fatalf("expect pair and value errors, got %v", err)
+	}
+
+	reader = newTestReader(t, "Sheet1",
+		[]string{"Counts"},
+		[]string{"a=1;b=2"},
+	)
+
+	reader.KeySplit = "="
+	reader.Splits = map[string]string{"Sheet1.Counts": ";"}
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(val.Counts, map[string]int{"a": 1, "b": 2}) {
+		t.Fatalf("unexpected counts: %v", val.Counts)
+	}
+}
+
+func TestReadAllSheets(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	sheet, err := reader.file.AddSheet("Sheet2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, val := range []string{"Name", "a", "b", "c"} {
+		sheet.AddRow().AddCell().SetString(val)
+	}
+
+	header, err := reader.file.AddSheet("HeaderOnly")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header.AddRow().AddCell().SetString("Name")
+
+	sheets := reader.ReadAllSheets()
+
+	if len(sheets) != 2 || len(sheets["Sheet1"]) != 2 || len(sheets["Sheet2"]) != 3 {
+		t.Fatalf("unexpected sheets: %v", sheets)
+	}
+
+	if _, ok := sheets["HeaderOnly"]; ok {
+		t.Fatal("expect sheet without data rows skipped")
+	}
+}
+
+func TestReadKeyed(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Age"},
+		[]string{"a", "30"},
+		[]string{"b", "40"},
+	)
+
+	vals, err := ReadKeyed[*nameRow](reader, "Sheet1", "Name")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vals) != 2 || vals["a"].Age != 30 || vals["b"].Age != 40 {
+		t.Fatalf("unexpected rows: %v", vals)
+	}
+
+	reader = newTestReader(t, "Sheet1",
+		[]string{"Name", "Age"},
+		[]string{"a", "30"},
+		[]string{"b", "40"},
+		[]string{"a", "50"},
+	)
+
+	_, err = ReadKeyed[nameRow](reader, "Sheet1", "Name")
+
+	if e, ok := err.(*MultiError); !ok || len(e.Errors()) != 1 || !strings.Contains(err.Error(), "duplicate key 'a'") {
+		t.Fatalf("expect duplicate key error, got %v", err)
+	}
+
+	if _, err := ReadKeyed[nameRow](reader, "Sheet1", "ID"); err == nil {
+		t.Fatal("expect key column not found error")
+	}
+}
+
+type blobRow struct {
+	Hash []byte
+}
+
+func TestReadBase64(t *testing.T) {
+	blob := []byte{0, 1, 2, 0xfb, 0xff, 'a', ','}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Hash"},
+		[]string{base64.StdEncoding.EncodeToString(blob)},
+		[]string{"not base64!"},
+		[]string{base64.URLEncoding.EncodeToString(blob)},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val blobRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(val.Hash, blob) {
+		t.Fatalf("unexpected blob: %v", val.Hash)
+	}
+
+	if err := rows[1].Read(&val); err == nil || !strings.Contains(err.Error(), "invalid base64") {
+		t.Fatalf("expect invalid base64 error, got %v", err)
+	}
+
+	reader.Base64 = base64.URLEncoding
+
+	if err := reader.Read("Sheet1")[2].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(val.Hash, blob) {
+		t.Fatalf("unexpected url encoded blob: %v", val.Hash)
+	}
+}
+
+type jsonMeta struct {
+	A int
+	B []int
+}
+
+type rawMeta struct {
+	raw string
+}
+
+func (m *rawMeta) UnmarshalJSON(data []byte) error {
+	m.raw = string(data)
+	return nil
+}
+
+type jsonRow struct {
+	Meta  jsonMeta               `xlsx:"Meta,json"`
+	Attrs map[string]interface{} `xlsx:"Attrs,json"`
+	Raw   rawMeta
+}
+
+func TestReadJSON(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Meta", "Attrs", "Raw"},
+		[]string{`{"a":1,"b":[2,3]}`, `{"a":1,"b":[2,3]}`, `[1, 2]`},
+		[]string{"", "", ""},
+		[]string{`{"a":`, "", ""},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val jsonRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(val.Meta, jsonMeta{1, []int{2, 3}}) {
+		t.Fatalf("unexpected Meta: %#v", val.Meta)
+	}
+
+	if !reflect.DeepEqual(val.Attrs, map[string]interface{}{"a": 1.0, "b": []interface{}{2.0, 3.0}}) {
+		t.Fatalf("unexpected Attrs: %#v", val.Attrs)
+	}
+
+	if val.Raw.raw != "[1, 2]" {
+		t.Fatalf("expect json.Unmarshaler called: %#v", val.Raw)
+	}
+
+	var empty jsonRow
+
+	if err := rows[1].Read(&empty); err != nil {
+		t.Fatal(err)
+	}
+
+	if empty.Attrs != nil {
+		t.Fatalf("expect nil map of empty cell: %#v", empty)
+	}
+
+	if err := rows[2].Read(&val); err == nil {
+		t.Fatal("expect invalid json error")
+	}
+}
+
+type hashRow struct {
+	Key  string
+	Hash [32]byte
+}
+
+// newHashReader create reader whose Hash column is converted by an expensive unmarshaler
+func newHashReader(tb testing.TB, rows int) *Reader {
+	file := x.NewFile()
+
+	sheet, _ := file.AddSheet("Sheet1")
+
+	header := sheet.AddRow()
+	header.AddCell().SetString("Key")
+	header.AddCell().SetString("Hash")
+
+	for i := 0; i < rows; i++ {
+		row := sheet.AddRow()
+		row.AddCell().SetString(fmt.Sprint(i))
+		row.AddCell().SetString(fmt.Sprint(i))
+	}
+
+	reader := newReader(file)
+
+	reader.RegisterType(reflect.TypeOf([32]byte{}), func(val reflect.Value, cell string) error {
+		sum := sha256.Sum256([]byte(cell))
+
+		for i := 0; i < 1000; i++ {
+			sum = sha256.Sum256(sum[:])
+		}
+
+		val.Set(reflect.ValueOf(sum))
+
+		return nil
+	})
+
+	return reader
+}
+
+func TestReadParallel(t *testing.T) {
+	reader := newHashReader(t, 100)
+
+	serial, err := ReadAll[hashRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parallel, err := ReadParallel[hashRow](reader, "Sheet1", 4)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Fatal("expect parallel rows equal to serial rows")
+	}
+
+	reader = newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"a"},
+		[]string{"3"},
+		[]string{"b"},
+	)
+
+	_, err = ReadParallel[countRow](reader, "Sheet1", 0)
+
+	if e, ok := err.(*MultiError); !ok || len(e.Errors()) != 2 || !strings.Contains(e.Errors()[0].Error(), "'a'") {
+		t.Fatalf("expect row errors in order, got %v", err)
+	}
+}
+
+func BenchmarkReadSerial(b *testing.B) {
+	reader := newHashReader(b, 1000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadAll[hashRow](reader, "Sheet1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadParallel(b *testing.B) {
+	reader := newHashReader(b, 1000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadParallel[hashRow](reader, "Sheet1", 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReadPatternStr(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Values", "Pointers"},
+		[]string{"1:2,3:4", "5-6"},
+	)
+
+	reader.Pattern = map[string]*regexp.Regexp{
+		"Sheet1.Values": regexp.MustCompile(`(\d+):(\d+)`),
+	}
+
+	reader.PatternStr = map[string]string{
+		"Sheet1.Values":   `invalid(`,
+		"Sheet1.Pointers": `(\d+)-(\d+)`,
+	}
+
+	var val structSliceRow
+
+	for i := 0; i < 2; i++ {
+		if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !reflect.DeepEqual(val.Values, []point{{1, 2}, {3, 4}}) {
+		t.Fatalf("expect compiled Pattern precedence: %v", val.Values)
+	}
+
+	if !reflect.DeepEqual(val.Pointers, []*point{{5, 6}}) {
+		t.Fatalf("unexpected pointers: %v", val.Pointers)
+	}
+
+	if _, ok := reader.patterns.Load(`(\d+)-(\d+)`); !ok {
+		t.Fatal("expect compiled pattern cached")
+	}
+
+	reader.PatternStr["Sheet1.Pointers"] = `(\d+`
+
+	err := reader.Read("Sheet1")[0].Read(&val)
+
+	if err == nil || !strings.Contains(err.Error(), "invalid pattern of column Sheet1.Pointers") {
+		t.Fatalf("expect invalid pattern error, got %v", err)
+	}
+}
+
+type complexRow struct {
+	Z complex128
+	W complex64
+}
+
+func TestReadComplex(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Z", "W"},
+		[]string{"3+4i", "-1-2i"},
+		[]string{"", ""},
+		[]string{"3+x", ""},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val complexRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (complexRow{3 + 4i, -1 - 2i}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	if err := rows[1].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (complexRow{}) {
+		t.Fatalf("expect zero of empty cells: %#v", val)
+	}
+
+	if err := rows[2].Read(&val); err == nil || !strings.Contains(err.Error(), "cell[Sheet1.Z:2(A4)] '3+x' to complex128") {
+		t.Fatalf("expect complex error, got %v", err)
+	}
+}
+
+func TestRowReaderUnmarshal(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count", "Extra"},
+		[]string{"1", "x"},
+	)
+
+	reader.DisallowUnknownColumns = true
+
+	row := reader.Read("Sheet1")[0]
+
+	var val countRow
+
+	if err := row.Unmarshal(&val); err == nil {
+		t.Fatal("expect unknown columns error")
+	} else if _, ok := err.(*ErrUnknownColumns); !ok {
+		t.Fatalf("expect *ErrUnknownColumns untouched, got %T", err)
+	}
+
+	if err := row.Unmarshal(val); err == nil {
+		t.Fatal("expect invalid unmarshal error")
+	} else if _, ok := err.(*ErrInvalidUnmarshal); !ok {
+		t.Fatalf("expect *ErrInvalidUnmarshal untouched, got %T", err)
+	}
+
+	reader.DisallowUnknownColumns = false
+
+	reader.Unmarshalers = map[string]UnmarshalF{
+		"Sheet1.Count": func(reflect.Value, string) error {
+			panic("unmarshaler bug")
+		},
+	}
+
+	row = reader.Read("Sheet1")[0]
+
+	if err := row.Read(&val); err == nil || !strings.Contains(err.Error(), "unmarshaler bug") {
+		t.Fatalf("expect recovered panic of Read, got %v", err)
+	}
+
+	defer func() {
+		if e := recover(); e != "unmarshaler bug" {
+			t.Fatalf("expect panic propagated by Unmarshal, got %v", e)
+		}
+	}()
+
+	row.Unmarshal(&val)
+
+	t.Fatal("expect panic")
+}
+
+type unexportedRow struct {
+	Name  string
+	count int
+}
+
+func TestReadUnexportedField(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "count"},
+		[]string{"a", "1"},
+	)
+
+	var val unexportedRow
+
+	err := reader.Read("Sheet1")[0].Unmarshal(&val)
+
+	e, ok := err.(*ErrUnmarshalField)
+
+	if !ok {
+		t.Fatalf("expect *ErrUnmarshalField, got %v", err)
+	}
+
+	if e.Key != "count" || e.Type != reflect.TypeOf(val) || e.Field.Name != "count" {
+		t.Fatalf("unexpected error fields: %#v", e)
+	}
+
+	if val.Name != "a" || val.count != 0 {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+}
+
+type lineItem struct {
+	Item  string
+	Price float64
+}
+
+type orderRow struct {
+	Order string
+	Items []lineItem `xlsx:",group"`
+}
+
+func TestReadColumnGroup(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Order", "Item1", "Price1", "Item2", "Price2"},
+		[]string{"a", "apple", "1.5", "pear", "2"},
+		[]string{"b", "plum", "3", "", ""},
+		[]string{"c"},
+	)
+
+	var vals []orderRow
+
+	var val orderRow
+
+	for _, row := range reader.Read("Sheet1") {
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		vals = append(vals, val)
+	}
+
+	expect := []orderRow{
+		{"a", []lineItem{{"apple", 1.5}, {"pear", 2}}},
+		{"b", []lineItem{{"plum", 3}}},
+		{"c", nil},
+	}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %#v", vals)
+	}
+}
+
+func TestReadSkipRows(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Title"},
+		[]string{"Name", "Age"},
+		[]string{"(text)", "(years)"},
+		[]string{"a", "30"},
+		[]string{"", ""},
+		[]string{"b", "x"},
+	)
+
+	reader.HeaderRow = 1
+	reader.SkipRows = 1
+	reader.SkipBlankRows = true
+
+	rows := reader.Read("Sheet1")
+
+	if len(rows) != 2 {
+		t.Fatalf("expect 2 rows, got %d", len(rows))
+	}
+
+	var val nameRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (nameRow{"a", 30}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	if err := rows[1].Read(&val); err == nil || !strings.Contains(err.Error(), "cell[Sheet1.Age:2(B6)]") {
+		t.Fatalf("expect cell error with sheet row, got %v", err)
+	}
+
+	reader.SkipRows = 4
+
+	if rows := reader.Read("Sheet1"); rows != nil {
+		t.Fatalf("expect no data rows, got %d", len(rows))
+	}
+}
+
+type pivotRow struct {
+	Region string
+	Q1     int `xlsx:"2023 Q1"`
+	Q2     int `xlsx:"2023 Q2"`
+	Next   int
+}
+
+func TestReadHeaderRows(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Region", "2023", "", "2024"},
+		[]string{"", "Q1", "Q2", "Q1"},
+		[]string{"north", "1", "2", "3"},
+	)
+
+	reader.file.Sheets[0].Rows[0].Cells[1].HMerge = 1
+
+	reader.HeaderRows = 2
+
+	reader.NameMapping = map[string]string{
+		"Sheet1.2024 Q1": "Next",
+	}
+
+	rows := reader.Read("Sheet1")
+
+	if len(rows) != 1 {
+		t.Fatalf("expect 1 row, got %d", len(rows))
+	}
+
+	if cols := rows[0].Columns(); !reflect.DeepEqual(cols, []string{"Region", "2023 Q1", "2023 Q2", "Next"}) {
+		t.Fatalf("unexpected columns: %v", cols)
+	}
+
+	var val pivotRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (pivotRow{"north", 1, 2, 3}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	reader.HeaderSeparator = "/"
+
+	if cols := reader.Read("Sheet1")[0].Columns(); cols[1] != "2023/Q1" {
+		t.Fatalf("unexpected composite column: %v", cols)
+	}
+}
+
+func TestReadAllRowType(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+	)
+
+	_, err := ReadAll[int](reader, "Sheet1")
+
+	if e, ok := err.(*ErrInvalidRowType); !ok || e.Type != reflect.TypeOf(0) || !strings.Contains(err.Error(), "invalid row type int") {
+		t.Fatalf("expect ErrInvalidRowType, got %v", err)
+	}
+
+	if _, err := ReadKeyed[[]countRow](reader, "Sheet1", "Count"); err == nil {
+		t.Fatal("expect invalid row type of ReadKeyed")
+	}
+
+	if _, err := ReadParallel[**countRow](reader, "Sheet1", 1); err == nil {
+		t.Fatal("expect invalid row type of ReadParallel")
+	}
+
+	if _, err := ReadAll[*countRow](reader, "Sheet1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type originRow struct {
+	Count  int
+	Sheet  string `xlsx:"-,sheet"`
+	RowNum int    `xlsx:"-,rownum"`
+}
+
+func TestReadOrigin(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	sheet, err := reader.file.AddSheet("Sheet2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, val := range []string{"Count", "3"} {
+		sheet.AddRow().AddCell().SetString(val)
+	}
+
+	var vals []originRow
+
+	for _, name := range reader.SheetNames() {
+		rows, err := ReadAll[originRow](reader, name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vals = append(vals, rows...)
+	}
+
+	expect := []originRow{{1, "Sheet1", 2}, {2, "Sheet1", 3}, {3, "Sheet2", 2}}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %#v", vals)
+	}
+}
+
+type zipRow struct {
+	Zip     string `xlsx:"Zip,text"`
+	SKU     string `xlsx:"SKU,text"`
+	Raw     string
+	Missing string `xlsx:"Missing,text"`
+}
+
+func TestReadText(t *testing.T) {
+	file := x.NewFile()
+
+	sheet, err := file.AddSheet("Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := sheet.AddRow()
+
+	for _, name := range []string{"Zip", "SKU", "Raw"} {
+		header.AddCell().SetString(name)
+	}
+
+	row := sheet.AddRow()
+	row.AddCell().SetFloatWithFormat(1234, "00000")
+	row.AddCell().SetString("007")
+	row.AddCell().SetFloatWithFormat(1234, "00000")
+
+	var val zipRow
+
+	if err := newReader(file).Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (zipRow{"01234", "007", "1234", ""}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+}
+
+type endpointRow struct {
+	Addr net.IP
+	Home url.URL
+	API  *url.URL
+}
+
+func TestReadNetTypes(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Addr", "Home", "API"},
+		[]string{"10.0.0.1", "https://example.com/a?b=1", "http://[::1]:8080/v1"},
+		[]string{"", "", ""},
+		[]string{"10.0.0.256", "", ""},
+		[]string{"::1", "", "http://%zz"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val endpointRow
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !val.Addr.Equal(net.IPv4(10, 0, 0, 1)) || val.Home.Host != "example.com" || val.Home.RawQuery != "b=1" {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+
+	if val.API == nil || val.API.Port() != "8080" || val.API.Path != "/v1" {
+		t.Fatalf("unexpected API: %v", val.API)
+	}
+
+	var empty endpointRow
+
+	if err := rows[1].Read(&empty); err != nil {
+		t.Fatal(err)
+	}
+
+	if empty.Addr != nil || empty.Home != (url.URL{}) || empty.API != nil {
+		t.Fatalf("expect empty values: %#v", empty)
+	}
+
+	if err := rows[2].Read(&val); err == nil || !strings.Contains(err.Error(), "cell[Sheet1.Addr:2(A4)] '10.0.0.256' to net.IP") {
+		t.Fatalf("expect invalid IP error, got %v", err)
+	}
+
+	if err := rows[3].Read(&val); err == nil || !strings.Contains(err.Error(), "cell[Sheet1.API:3(C5)]") {
+		t.Fatalf("expect invalid URL error, got %v", err)
+	}
+}
+
+func TestReadContinueOnError(t *testing.T) {
+	rows := [][]string{{"Count"}}
+
+	for i := 0; i < 11; i++ {
+		if i == 4 {
+			rows = append(rows, []string{"four"})
+		} else {
+			rows = append(rows, []string{fmt.Sprint(i)})
+		}
+	}
+
+	reader := newTestReader(t, "Sheet1", rows...)
+
+	if vals, err := ReadAll[countRow](reader, "Sheet1"); err == nil || vals != nil {
+		t.Fatalf("expect ReadAll aborted, got %v %v", vals, err)
+	}
+
+	reader.ContinueOnError = true
+
+	vals, err := ReadAll[countRow](reader, "Sheet1")
+
+	if len(vals) != 10 {
+		t.Fatalf("expect 10 good rows, got %d", len(vals))
+	}
+
+	e, ok := err.(*MultiError)
+
+	if !ok {
+		t.Fatalf("expect MultiError, got %v", err)
+	}
+
+	rowErrs := e.RowErrors()
+
+	if len(rowErrs) != 1 || rowErrs[0].Row != 4 || rowErrs[0].Sheet != "Sheet1" || !strings.Contains(rowErrs[0].Err.Error(), "'four'") {
+		t.Fatalf("unexpected row errors: %v", rowErrs)
+	}
+
+	if parallel, err := ReadParallel[countRow](reader, "Sheet1", 2); err == nil || !reflect.DeepEqual(parallel, vals) {
+		t.Fatalf("expect parallel good rows, got %v %v", parallel, err)
+	}
+}
+
+type accountStatus int
+
+const (
+	statusActive accountStatus = iota + 1
+	statusSuspended
+	statusClosed
+)
+
+func TestRegisterEnum(t *testing.T) {
+	type account struct {
+		Name   string
+		Status accountStatus
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Status"},
+		[]string{"a", "Active"},
+		[]string{"b", "Suspended"},
+		[]string{"c", "Closed"},
+		[]string{"d", "Deleted"},
+	)
+
+	reader.RegisterEnum(reflect.TypeOf(accountStatus(0)), map[string]int64{
+		"Active":    int64(statusActive),
+		"Suspended": int64(statusSuspended),
+		"Closed":    int64(statusClosed),
+	})
+
+	rows := reader.Read("Sheet1")
+
+	expect := []accountStatus{statusActive, statusSuspended, statusClosed}
+
+	for i, status := range expect {
+		var val account
+
+		if err := rows[i].Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		if val.Status != status {
+			t.Fatalf("expect status %d, got %d", status, val.Status)
+		}
+	}
+
+	var val account
+
+	err := rows[3].Read(&val)
+
+	if err == nil || !strings.Contains(err.Error(), "'Deleted'") || !strings.Contains(err.Error(), "Active, Closed, Suspended") {
+		t.Fatalf("expect unknown enum error, got %v", err)
+	}
+}
+
+func TestReadLocaleSeparators(t *testing.T) {
+	type localeRow struct {
+		Amount  float64
+		Amounts []float64
+		Counts  []int `xlsx:",split:|"`
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Amount", "Amounts", "Counts"},
+		[]string{"1.234,56", "1.234,56;0,5", "1.000|2"},
+	)
+
+	reader.DecimalSeparator = ','
+	reader.ThousandsSeparator = '.'
+
+	var val localeRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(val, localeRow{1234.56, []float64{1234.56, 0.5}, []int{1000, 2}}) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+}
+
+func TestReadAllMust(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	if vals := ReadAllMust[countRow](reader, "Sheet1"); len(vals) != 2 || vals[1].Count != 2 {
+		t.Fatalf("unexpected rows: %v", vals)
+	}
+
+	defer func() {
+		if _, ok := recover().(*ErrSheetNotFound); !ok {
+			t.Fatal("expect ErrSheetNotFound panic")
+		}
+	}()
+
+	ReadAllMust[countRow](reader, "Sheet2")
+}
+
+func TestReadNestedLazyPointer(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Office.City", "Office.Geo.Lat", "Office.Geo.Lng", "Owner"},
+		[]string{"a", "", "", "", ""},
+		[]string{"b", "Lyon", "", "", "c"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var empty personRow
+
+	if err := rows[0].Read(&empty); err != nil {
+		t.Fatal(err)
+	}
+
+	if empty.Office != nil {
+		t.Fatalf("expect nil office, got %v", empty.Office)
+	}
+
+	var partial personRow
+
+	if err := rows[1].Read(&partial); err != nil {
+		t.Fatal(err)
+	}
+
+	if partial.Office == nil || partial.Office.City != "Lyon" || partial.Office.Geo != nil {
+		t.Fatalf("unexpected office: %v", partial.Office)
+	}
+
+	var embedded [2]embeddedRow
+
+	for i := range embedded {
+		if err := rows[i].Read(&embedded[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if embedded[0].Meta != nil || embedded[1].Meta == nil || embedded[1].Owner != "c" {
+		t.Fatalf("unexpected embedded meta: %v %v", embedded[0].Meta, embedded[1].Meta)
+	}
+}
+
+func TestRowCount(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"title"},
+		[]string{"Count"},
+		[]string{"units"},
+		[]string{"1"},
+		[]string{"2"},
+	)
+
+	if count := reader.RowCount("Sheet1"); count != 4 {
+		t.Fatalf("expect 4 rows, got %d", count)
+	}
+
+	reader.HeaderRow = 1
+	reader.SkipRows = 1
+
+	if count := reader.RowCount("Sheet1"); count != 2 || count != len(reader.Read("Sheet1")) {
+		t.Fatalf("expect 2 rows, got %d", count)
+	}
+
+	reader.SkipRows = 10
+
+	if count := reader.RowCount("Sheet1"); count != 0 {
+		t.Fatalf("expect 0 rows, got %d", count)
+	}
+
+	if count := reader.RowCount("Sheet2"); count != -1 {
+		t.Fatalf("expect -1 for missing sheet, got %d", count)
+	}
+}
+
+func TestReadStoredFloat(t *testing.T) {
+	type priceRow struct {
+		Price float64
+		Ptr   *float64
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Price", "Ptr"},
+	)
+
+	row := reader.file.Sheet["Sheet1"].AddRow()
+
+	row.AddCell().SetFloatWithFormat(12.30000001, "0.0")
+	row.AddCell().SetFloatWithFormat(0.1+0.2, "0.00")
+
+	if text, err := row.Cells[0].FormattedValue(); err != nil || text != "12.3" {
+		t.Fatalf("expect displayed 12.3, got %s %v", text, err)
+	}
+
+	var val priceRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Price != 12.30000001 || val.Ptr == nil || *val.Ptr != 0.1+0.2 {
+		t.Fatalf("unexpected row: %v %v", val.Price, val.Ptr)
+	}
+}
+
+type progressCount int
+
+func TestReadProgress(t *testing.T) {
+	rows := [][]string{{"Count"}}
+
+	for i := 0; i < 2500; i++ {
+		rows = append(rows, []string{fmt.Sprint(i)})
+	}
+
+	reader := newTestReader(t, "Sheet1", rows...)
+
+	var converted int64
+
+	reader.RegisterType(reflect.TypeOf(progressCount(0)), func(val reflect.Value, cell string) error {
+		atomic.AddInt64(&converted, 1)
+
+		n, err := strconv.Atoi(cell)
+		val.SetInt(int64(n))
+
+		return err
+	})
+
+	var calls [][2]int
+
+	reader.Progress = func(done, total int) {
+		// the progress counts the converted rows, not the scanned ones
+		if n := atomic.LoadInt64(&converted); n < int64(done) {
+			t.Errorf("progress %d reported before conversion, converted %d", done, n)
+		}
+
+		calls = append(calls, [2]int{done, total})
+	}
+
+	expect := [][2]int{{1000, 2500}, {2000, 2500}, {2500, 2500}}
+
+	type progressRow struct {
+		Count progressCount
+	}
+
+	reads := map[string]func() error{
+		"ReadAll": func() error {
+			_, err := ReadAll[progressRow](reader, "Sheet1")
+			return err
+		},
+		"ReadKeyed": func() error {
+			_, err := ReadKeyed[progressRow](reader, "Sheet1", "Count")
+			return err
+		},
+		"ReadParallel": func() error {
+			_, err := ReadParallel[progressRow](reader, "Sheet1", 4)
+			return err
+		},
+		"Validate": func() error {
+			return reader.Validate("Sheet1", progressRow{})
+		},
+	}
+
+	for name, read := range reads {
+		converted, calls = 0, nil
+
+		if err := read(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(calls, expect) {
+			t.Fatalf("%s: unexpected progress calls: %v", name, calls)
+		}
+	}
+
+	// the rows returned without conversion don't report progress
+	calls = nil
+
+	if _, err := reader.ReadSheet("Sheet1"); err != nil || calls != nil {
+		t.Fatalf("unexpected progress calls of ReadSheet: %v %v", calls, err)
+	}
+}
+
+func TestReadTrailingEmptyHeaders(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Count", "Active", "", " "},
+		[]string{"a", "1", "true", "", ""},
+	)
+
+	reader.DisallowUnknownColumns = true
+
+	row := reader.Read("Sheet1")[0]
+
+	if columns := row.Columns(); !reflect.DeepEqual(columns, []string{"Name", "Count", "Active"}) {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+
+	var val struct {
+		Name   string
+		Count  int
+		Active bool
+	}
+
+	if err := row.Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Name != "a" || val.Count != 1 || !val.Active {
+		t.Fatalf("unexpected row: %v", val)
+	}
+}
+
+func TestReadSpacerColumn(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "", "Count"},
+		[]string{"a", "note", "1"},
+	)
+
+	reader.DisallowUnknownColumns = true
+	reader.DisallowDuplicateColumns = true
+
+	row := reader.Read("Sheet1")[0]
+
+	var val struct {
+		Name  string
+		Count int
+	}
+
+	if err := row.Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Name != "a" || val.Count != 1 {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	if vals := row.ReadMap(); !reflect.DeepEqual(vals, map[string]string{"Name": "a", "Count": "1"}) {
+		t.Fatalf("unexpected map: %v", vals)
+	}
+}
+
+func TestSetHeader(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"N@me", "Cnt#"},
+		[]string{"1", "a"},
+		[]string{"2", "b"},
+	)
+
+	reader.SetHeader("Sheet1", []string{"A", "C"})
+	reader.SkipRows = 1
+
+	vals, err := ReadAll[multiRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vals) != 2 || vals[0].A != 1 || vals[1].C != "b" {
+		t.Fatalf("unexpected rows: %v", vals)
+	}
+
+	reader.SetHeader("Sheet1", []string{"A"})
+
+	if _, err := reader.ReadSheet("Sheet1"); err == nil || !strings.Contains(err.Error(), "supplied header") {
+		t.Fatalf("expect header width error, got %v", err)
+	}
+
+	reader.SetHeader("Sheet1", nil)
+	reader.SkipRows = 0
+
+	if rows := reader.Read("Sheet1"); len(rows) != 2 || rows[0].Columns()[0] != "N@me" {
+		t.Fatalf("expect sheet header restored, got %v", rows)
+	}
+}
+
+// rewriteZipEntry rewrite the entry of xlsx file by f, e.g. the shared strings part
+func rewriteZipEntry(t *testing.T, filename, entry string, f func(content string) string) {
+	src, err := zip.OpenReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer src.Close()
+
+	var buff bytes.Buffer
+
+	dst := zip.NewWriter(&buff)
+
+	found := false
+
+	for _, file := range src.File {
+		w, err := dst.Create(file.Name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := file.Open()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(r)
+
+		r.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if file.Name == entry {
+			content, found = []byte(f(string(content))), true
+		}
+
+		w.Write(content)
+	}
+
+	// the missing entry is added with the content of empty string
+	if !found {
+		w, err := dst.Create(entry)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.Write([]byte(f("")))
+	}
+
+	if err := dst.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filename, buff.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type linkRow struct {
+	Name     string
+	Homepage string `xlsx:"Homepage,hyperlink"`
+}
+
+func TestReadHyperlink(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hyperlink.xlsx")
+
+	writer := NewWriter(filename)
+
+	rows := []struct{ Name, Homepage string }{{"a", "Site A"}, {"b", "Plain"}, {"c", "Sheet"}}
+
+	if err := writer.Write("Sheet1", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rewriteZipEntry(t, filename, "xl/worksheets/sheet1.xml", func(content string) string {
+		return strings.Replace(content, "</sheetData>", `</sheetData><hyperlinks xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+			`<hyperlink ref="B2" r:id="rId1"/><hyperlink ref="B4" location="Sheet1!A1"/></hyperlinks>`, 1)
+	})
+
+	rewriteZipEntry(t, filename, "xl/worksheets/_rels/sheet1.xml.rels", func(string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://a.example.com/" TargetMode="External"/>
+</Relationships>`
+	})
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vals, err := ReadAll[linkRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cell without hyperlink falls back to the display value
+	expect := []linkRow{
+		{"a", "https://a.example.com/"},
+		{"b", "Plain"},
+		{"c", "Sheet1!A1"},
+	}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %#v", vals)
+	}
+
+	content, err := os.ReadFile(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reader, err = NewReaderFromBinary(content); err != nil {
+		t.Fatal(err)
+	}
+
+	if vals, err = ReadAll[linkRow](reader, "Sheet1"); err != nil || !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows from binary: %#v %v", vals, err)
+	}
+}
+
+func TestReadComment(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "comment.xlsx")
+
+	writer := NewWriter(filename)
+
+	if err := writer.Write("Sheet1", []struct{ Name string }{{"a"}, {"b"}, {"c"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rewriteZipEntry(t, filename, "xl/worksheets/_rels/sheet1.xml.rels", func(string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments" Target="../comments1.xml"/>
+</Relationships>`
+	})
+
+	rewriteZipEntry(t, filename, "xl/comments1.xml", func(string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<comments xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<authors><author>qa</author></authors>
+<commentList>
+<comment ref="A2" authorId="0"><text><t>looks good</t></text></comment>
+<comment ref="A4" authorId="0"><text><r><rPr><b/></rPr><t xml:space="preserve">needs </t></r><r><t>review</t></r></text></comment>
+</commentList>
+</comments>`
+	})
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type commentRow struct {
+		Review string `xlsx:"Name,comment"`
+	}
+
+	vals, err := ReadAll[commentRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cell without comment is empty
+	expect := []commentRow{{"looks good"}, {""}, {"needs review"}}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %#v", vals)
+	}
+}
+
+func TestReadRichText(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rich.xlsx")
+
+	writer := NewWriter(filename)
+
+	if err := writer.Write("Sheet1", []struct{ Title string }{{"plain"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rewriteZipEntry(t, filename, "xl/sharedStrings.xml", func(string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="2" uniqueCount="2">
+<si><t>Title</t></si>
+<si><r><rPr><b/></rPr><t xml:space="preserve">Hello </t></r><r><rPr><i/></rPr><t>World</t></r></si>
+</sst>`
+	})
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var val struct{ Title string }
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Title != "Hello World" {
+		t.Fatalf("expect concatenated rich text, got '%s'", val.Title)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"A", "B", "C"},
+		[]string{"1", "1.5", "a"},
+		[]string{"x", "2.5", "b"},
+		[]string{"3", "3.5", "c"},
+		[]string{"4", "y", "d"},
+		[]string{"z", "w", "e"},
+	)
+
+	err := reader.Validate("Sheet1", &multiRow{})
+
+	e, ok := err.(*MultiError)
+
+	if !ok {
+		t.Fatalf("expect MultiError, got %v", err)
+	}
+
+	rowErrs := e.RowErrors()
+
+	if len(rowErrs) != 3 || rowErrs[0].Row != 1 || !strings.Contains(rowErrs[0].Error(), "A3") || rowErrs[1].Row != 3 || !strings.Contains(rowErrs[1].Error(), "B5") {
+		t.Fatalf("unexpected errors: %v", rowErrs)
+	}
+
+	if last := rowErrs[2].Error(); rowErrs[2].Row != 4 || !strings.Contains(last, "A6") || !strings.Contains(last, "B6") {
+		t.Fatalf("expect both bad cells of row reported, got %v", rowErrs[2])
+	}
+
+	if err := reader.Validate("Sheet2", multiRow{}); err == nil {
+		t.Fatal("expect sheet not found error")
+	}
+
+	if err := reader.Validate("Sheet1", 1); err == nil {
+		t.Fatal("expect invalid type error")
+	}
+}
+
+func TestReadTimeLayouts(t *testing.T) {
+	type dateRow struct {
+		D time.Time `xlsx:"D,layout:2006-01-02|01/02/2006"`
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"D"},
+		[]string{"2023-01-02"},
+		[]string{"01/02/2023"},
+		[]string{"2 Jan 2023"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	expect := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	for _, row := range rows[:2] {
+		var val dateRow
+
+		if err := row.Read(&val); err != nil {
+			t.Fatal(err)
+		}
+
+		if !val.D.Equal(expect) {
+			t.Fatalf("expect %v, got %v", expect, val.D)
+		}
+	}
+
+	var val dateRow
+
+	if err := rows[2].Read(&val); err == nil || !strings.Contains(err.Error(), "tried layouts 2006-01-02, 01/02/2006") {
+		t.Fatalf("expect layouts error, got %v", err)
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"A", "B", "C"},
+		[]string{"1", "1.5", "a"},
+		[]string{"x", "y", "b"},
+	)
+
+	reader.CollectErrors = true
+	reader.ContinueOnError = true
+
+	vals, err := ReadAll[multiRow](reader, "Sheet1")
+
+	if len(vals) != 1 {
+		t.Fatalf("expect 1 good row, got %v", vals)
+	}
+
+	errs := FieldErrors(err)
+
+	if len(errs) != 2 {
+		t.Fatalf("expect 2 cell errors, got %v", err)
+	}
+
+	expect := []CellError{{Sheet: "Sheet1", Row: 1, Column: "A", A1: "A3"}, {Sheet: "Sheet1", Row: 1, Column: "B", A1: "B3"}}
+
+	for i, e := range errs {
+		if e.Err == nil || e.Sheet != expect[i].Sheet || e.Row != expect[i].Row || e.Column != expect[i].Column || e.A1 != expect[i].A1 {
+			t.Fatalf("unexpected cell error: %#v", e)
+		}
+	}
+
+	if e := err.(*MultiError); len(e.FieldErrors()) != 2 {
+		t.Fatalf("expect MultiError.FieldErrors, got %v", e.FieldErrors())
+	}
+
+	reader.CollectErrors = false
+
+	if err := reader.Read("Sheet1")[1].Read(&multiRow{}); len(FieldErrors(err)) != 1 || FieldErrors(err)[0].Column != "A" {
+		t.Fatalf("expect single cell error, got %v", err)
+	}
+}
+
+func TestReadPercentCells(t *testing.T) {
+	type rateRow struct {
+		Rate  float64
+		Whole int
+		Text  float64
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Rate", "Whole", "Text"},
+	)
+
+	row := reader.file.Sheet["Sheet1"].AddRow()
+
+	row.AddCell().SetFloatWithFormat(0.12, "0%")
+	row.AddCell().SetFloatWithFormat(0.5, "0.00%")
+	row.AddCell().SetString("12.5%")
+
+	reader.FormattedNumbers = true
+
+	var val rateRow
+
+	// the int field can't hold the fraction 0.5
+	if err := reader.Read("Sheet1")[0].Read(&val); err == nil {
+		t.Fatal("expect fraction to int error")
+	}
+
+	reader.PercentAsFraction = false
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val != (rateRow{12, 50, 12.5}) {
+		t.Fatalf("unexpected whole percent row: %v", val)
+	}
+
+	reader.PercentAsFraction = true
+
+	var fraction struct {
+		Rate float64
+		Text float64
+	}
+
+	if err := reader.Read("Sheet1")[0].Read(&fraction); err != nil {
+		t.Fatal(err)
+	}
+
+	if fraction.Rate != 0.12 || fraction.Text != 0.125 {
+		t.Fatalf("unexpected fraction row: %v", fraction)
+	}
+}
+
+func TestReadInterface(t *testing.T) {
+	type anyRow struct {
+		Int    interface{}
+		Float  interface{}
+		Bool   interface{}
+		String interface{}
+		Empty  interface{}
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Int", "Float", "Bool", "String", "Empty"},
+	)
+
+	row := reader.file.Sheet["Sheet1"].AddRow()
+
+	row.AddCell().SetInt(42)
+	row.AddCell().SetFloat(1.5)
+	row.AddCell().SetBool(true)
+	row.AddCell().SetString("12")
+	row.AddCell()
+
+	val := anyRow{Empty: "stale"}
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := anyRow{int64(42), 1.5, true, "12", nil}
+
+	if !reflect.DeepEqual(val, expect) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+}
+
+func TestRequireAllFields(t *testing.T) {
+	type legacyRow struct {
+		Name   string
+		Office *address
+		Base
+		Added int
+		Items []lineItem `xlsx:",group"`
+		Sheet string     `xlsx:"-,sheet"`
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Office.City", "ID", "Price1"},
+		[]string{"a", "Paris", "1", "2"},
+	)
+
+	var val legacyRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	reader.RequireAllFields = true
+
+	err := reader.Read("Sheet1")[0].Read(&val)
+
+	if e, ok := err.(*ErrMissingFields); !ok || !reflect.DeepEqual(e.Fields, []string{"Kind", "Added"}) {
+		t.Fatalf("expect missing Kind, Added, got %v", err)
+	}
+}
+
+func TestReadSplitRegexp(t *testing.T) {
+	type tagsRow struct {
+		Tags  []string
+		Ints  [3]int
+		Pairs []string `xlsx:",split:|"`
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Tags", "Ints", "Pairs"},
+		[]string{"a;  b ,c", "1 ; 2,3", "x, y|z"},
+	)
+
+	reader.SplitRegexp = regexp.MustCompile(`\s*[,;]\s*`)
+
+	var val tagsRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := tagsRow{[]string{"a", "b", "c"}, [3]int{1, 2, 3}, []string{"x, y", "z"}}
+
+	if !reflect.DeepEqual(val, expect) {
+		t.Fatalf("unexpected row: %#v", val)
+	}
+}
+
+func TestReadMultipleTypes(t *testing.T) {
+	type summaryRow struct {
+		Name  string
+		Total float64
+	}
+
+	type detailRow struct {
+		Name   string
+		Count  int
+		Price  float64
+		Totals float64 `xlsx:"Total"`
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Name", "Count", "Price", "Total"},
+		[]string{"a", "2", "1.5", "3"},
+		[]string{"b", "4", "0.5", "2"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 2*len(rows)*4)
+
+	for i := 0; i < 4; i++ {
+		for _, row := range rows {
+			wg.Add(2)
+
+			go func(row *RowReader) {
+				defer wg.Done()
+
+				var val summaryRow
+
+				if err := row.Read(&val); err != nil || val.Name == "" || val.Total == 0 {
+					errs <- fmt.Errorf("unexpected summary %v: %v", val, err)
+				}
+			}(row)
+
+			go func(row *RowReader) {
+				defer wg.Done()
+
+				var val detailRow
+
+				if err := row.Read(&val); err != nil || float64(val.Count)*val.Price != val.Totals {
+					errs <- fmt.Errorf("unexpected detail %v: %v", val, err)
+				}
+			}(row)
+		}
+	}
+
+	wg.Wait()
+
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestReadNullValues(t *testing.T) {
+	type nullRow struct {
+		Count int
+		Price *float64
+		Name  string
+		Code  string `xlsx:"Code,required"`
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count", "Price", "Name", "Code"},
+		[]string{"NULL", "n/a", " N/A ", "x"},
+		[]string{"1", "2.5", "a", "null"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	var val nullRow
+
+	if err := rows[0].Read(&val); err == nil {
+		t.Fatal("expect NULL parse error")
+	}
+
+	reader.NullValues = []string{"NULL", "N/A"}
+
+	rows = reader.Read("Sheet1")
+
+	val = nullRow{Count: 9}
+
+	if err := rows[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Count != 0 || val.Price != nil || val.Name != "" || val.Code != "x" {
+		t.Fatalf("unexpected row: %v", val)
+	}
+
+	if err := rows[1].Read(&val); err == nil || !strings.Contains(err.Error(), "is required") {
+		t.Fatalf("expect required error, got %v", err)
+	}
+}
+
+func TestReadErrorCells(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "error.xlsx")
+
+	writer := NewWriter(filename)
+
+	if err := writer.Write("Sheet1", []multiRow{{1, 2.5, "a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// B2 is the formula error result of =1/0
+	rewriteZipEntry(t, filename, "xl/worksheets/sheet1.xml", func(content string) string {
+		return regexp.MustCompile(`<c r="B2"[^>]*>.*?</c>`).ReplaceAllString(content, `<c r="B2" t="e"><f>1/0</f><v>#DIV/0!</v></c>`)
+	})
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	val := multiRow{B: 9}
+
+	err = reader.Read("Sheet1")[0].Read(&val)
+
+	if errs := FieldErrors(err); len(errs) != 1 {
+		t.Fatalf("expect one cell error, got %v", err)
+	} else if e, ok := errs[0].Err.(*ErrCellError); !ok || e.Sheet != "Sheet1" || e.A1 != "B2" || e.Code != "#DIV/0!" {
+		t.Fatalf("expect ErrCellError, got %v", err)
+	}
+
+	reader.ErrorCells = ErrorCellSkip
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil || val.B != 9 || val.A != 1 {
+		t.Fatalf("expect untouched field, got %v %v", val, err)
+	}
+
+	reader.ErrorCells = ErrorCellAsEmpty
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil || val.B != 0 || val.C != "a" {
+		t.Fatalf("expect zero field, got %v %v", val, err)
+	}
+}
+
+func TestRejectNonFinite(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"B"},
+		[]string{"NaN"},
+		[]string{"+Inf"},
+		[]string{"1.5"},
+	)
+
+	rows := reader.Read("Sheet1")
+
+	for _, row := range rows {
+		if err := row.Read(&multiRow{}); err != nil {
+			t.Fatalf("expect permissive non-finite floats, got %v", err)
+		}
+	}
+
+	reader.RejectNonFinite = true
+
+	rows = reader.Read("Sheet1")
+
+	for i, ref := range []string{"A2", "A3"} {
+		if err := rows[i].Read(&multiRow{}); err == nil || !strings.Contains(err.Error(), ref) || !strings.Contains(err.Error(), "non-finite") {
+			t.Fatalf("expect non-finite error of %s, got %v", ref, err)
+		}
+	}
+
+	var val multiRow
+
+	if err := rows[2].Read(&val); err != nil || val.B != 1.5 {
+		t.Fatalf("unexpected row: %v %v", val, err)
+	}
+}
+
+func TestReaderFileSheet(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"42"},
+	)
+
+	if reader.File() == nil || len(reader.File().Sheets) != 1 {
+		t.Fatal("expect underlying file")
+	}
+
+	sheet := reader.Sheet("Sheet1")
+
+	if sheet == nil {
+		t.Fatal("expect underlying sheet")
+	}
+
+	if cell := sheet.Cell(1, 0); cell.Value != "42" {
+		t.Fatalf("unexpected cell: %s", cell.Value)
+	}
+
+	if reader.Sheet("Sheet2") != nil {
+		t.Fatal("expect nil of missing sheet")
+	}
+}
+
+func TestReadCompositeKey(t *testing.T) {
+	type memberRow struct {
+		FirstName string `xlsx:"First Name"`
+		LastName  string `xlsx:"Last Name"`
+		Age       int
+		Key       string `xlsx:"-,key:First Name+Last Name"`
+		AgeKey    string `xlsx:"-,key:LastName+Age,sep:/"`
+	}
+
+	reader := newTestReader(t, "Sheet1",
+		[]string{"First Name", "Last Name", "Age"},
+		[]string{"Ada ", "Lovelace", "36"},
+	)
+
+	reader.NameMapping = map[string]string{"Sheet1.Last Name": "LastName"}
+
+	var val memberRow
+
+	if err := reader.Read("Sheet1")[0].Read(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Key != "Ada|Lovelace" || val.AgeKey != "Lovelace/36" {
+		t.Fatalf("unexpected keys: %q %q", val.Key, val.AgeKey)
+	}
+
+	var missing struct {
+		Key string `xlsx:"-,key:First Name+Nick"`
+	}
+
+	if err := reader.Read("Sheet1")[0].Read(&missing); err == nil || !strings.Contains(err.Error(), "key column Nick") {
+		t.Fatalf("expect missing key column error, got %v", err)
+	}
+}
+
+func TestReadLocaleNumericCells(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Amount", "Amounts", "Counts"},
+		[]string{"1,5", "1.234,5;0,5", "1.000|2"},
+	)
+
+	row := reader.file.Sheet["Sheet1"].AddRow()
+
+	row.AddCell().SetFloatWithFormat(1234.56, "#,##0.00")
+	row.AddCell().SetFloat(0.25)
+	row.AddCell().SetInt(1000)
+
+	// the thousands separator is derived as '.'
+	reader.DecimalSeparator = ','
+
+	type localeRow struct {
+		Amount  float64
+		Amounts []float64
+		Counts  []int `xlsx:",split:|"`
+	}
+
+	rows := reader.Read("Sheet1")
+
+	var text localeRow
+
+	if err := rows[0].Read(&text); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(text, localeRow{1.5, []float64{1234.5, 0.5}, []int{1000, 2}}) {
+		t.Fatalf("unexpected text row: %#v", text)
+	}
+
+	var numeric localeRow
+
+	if err := rows[1].Read(&numeric); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(numeric, localeRow{1234.56, []float64{0.25}, []int{1000}}) {
+		t.Fatalf("unexpected numeric row: %#v", numeric)
+	}
+}
+
+func TestReadAllSheetErrors(t *testing.T) {
+	reader := newTestReader(t, "Sheet1",
+		[]string{"Count"},
+		[]string{"1"},
+	)
+
+	if vals, err := ReadAll[countRow](reader, "Typo"); vals != nil || err == nil {
+		t.Fatalf("expect ReadAll sheet not found, got %v %v", vals, err)
+	} else if _, ok := err.(*ErrSheetNotFound); !ok {
+		t.Fatalf("expect ErrSheetNotFound, got %v", err)
+	}
+
+	if vals, err := ReadKeyed[countRow](reader, "Typo", "Count"); vals != nil || err == nil {
+		t.Fatalf("expect ReadKeyed sheet not found, got %v %v", vals, err)
+	}
+
+	if vals, err := ReadParallel[countRow](reader, "Typo", 2); vals != nil || err == nil {
+		t.Fatalf("expect ReadParallel sheet not found, got %v %v", vals, err)
+	}
+
+	reader.HeaderRow = 5
+
+	if _, err := ReadAll[countRow](reader, "Sheet1"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("expect header row error, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expect ReadAllMust panic on header row error")
+		}
+	}()
+
+	ReadAllMust[countRow](reader, "Sheet1")
+}
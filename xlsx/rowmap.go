@@ -0,0 +1,122 @@
+package xlsx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// columnName resolve the header name without struct type, the header name is
+// transformed by NameFunc, then mapped by NameMapping
+func (reader *RowReader) columnName(header string) string {
+
+	if reader.nameFunc != nil {
+		header = reader.nameFunc(header)
+	}
+
+	if name, ok := reader.nameMapping[fmt.Sprintf("%s.%s", reader.Sheet, header)]; ok {
+		return name
+	}
+
+	return header
+}
+
+// Columns get the resolved column names of header, the columns with empty name are skipped
+func (reader *RowReader) Columns() []string {
+
+	names := make([]string, 0, len(reader.header.Cells))
+
+	for _, cell := range reader.header.Cells {
+		if name := reader.columnName(cell.Value); strings.TrimSpace(name) != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Cell get the raw cell value of column, the column is matched by the resolved
+// column name or the header name, return false if the column not found
+func (reader *RowReader) Cell(column string) (string, bool) {
+
+	for i, cell := range reader.header.Cells {
+
+		if cell.Value != column && reader.columnName(cell.Value) != column {
+			continue
+		}
+
+		if i >= len(reader.row.Cells) {
+			return "", true
+		}
+
+		return reader.row.Cells[i].Value, true
+	}
+
+	return "", false
+}
+
+// ReadMap read row as map keyed by the resolved column names, the columns with
+// empty name are skipped. if multi header columns resolve to the same name, the
+// first column wins
+func (reader *RowReader) ReadMap() map[string]string {
+
+	vals := make(map[string]string)
+
+	for i, cell := range reader.header.Cells {
+
+		name := reader.columnName(cell.Value)
+
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+
+		if _, ok := vals[name]; ok {
+			reader.W("duplicate column(%s) of sheet %s, the first one wins", name, reader.Sheet)
+			continue
+		}
+
+		if i < len(reader.row.Cells) {
+			vals[name] = reader.row.Cells[i].Value
+		} else {
+			vals[name] = ""
+		}
+	}
+
+	return vals
+}
+
+// ReadMapInterface read row as map like ReadMap, the values are inferred by inferValue
+func (reader *RowReader) ReadMapInterface() map[string]interface{} {
+
+	vals := make(map[string]interface{})
+
+	for name, val := range reader.ReadMap() {
+		vals[name] = inferValue(val)
+	}
+
+	return vals
+}
+
+// inferValue infer go value of cell, integer as int64, other numbers as float64,
+// "true"/"false" in any case as bool, otherwise the string itself
+func inferValue(val string) interface{} {
+
+	if v, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return v
+	}
+
+	// the text like "nan" and "inf" is kept as string, not the non-finite float
+	if v, err := strconv.ParseFloat(val, 64); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+		return v
+	}
+
+	switch strings.ToLower(val) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	return val
+}
@@ -0,0 +1,66 @@
+package xlsx
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tagOptions the comma separated options of xlsx struct tag
+type tagOptions string
+
+// parseTag split xlsx struct tag into column name and options, the leading "col:N"
+// option like `xlsx:"col:2"` is not the column name, the field is matched by name
+func parseTag(tag string) (string, tagOptions) {
+	if strings.HasPrefix(tag, "col:") {
+		return "", tagOptions(tag)
+	}
+
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], tagOptions(tag[idx+1:])
+	}
+
+	return tag, tagOptions("")
+}
+
+// fieldTag get the parsed xlsx tag of struct field
+func fieldTag(field reflect.StructField) (string, tagOptions) {
+	return parseTag(field.Tag.Get("xlsx"))
+}
+
+// fieldColumn get the zero based column index of field tagged with "col:N", the
+// option can be the first part of tag, e.g. `xlsx:"col:2"`, return false if not tagged
+func fieldColumn(field reflect.StructField) (int, bool) {
+
+	for _, opt := range strings.Split(field.Tag.Get("xlsx"), ",") {
+		if strings.HasPrefix(opt, "col:") {
+			index, err := strconv.Atoi(opt[len("col:"):])
+
+			return index, err == nil && index >= 0
+		}
+	}
+
+	return 0, false
+}
+
+// Has check if the flag option exists, e.g. "required"
+func (opts tagOptions) Has(name string) bool {
+	for _, opt := range strings.Split(string(opts), ",") {
+		if opt == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get get the value of option "name:value", return empty string if not found
+func (opts tagOptions) Get(name string) string {
+	for _, opt := range strings.Split(string(opts), ",") {
+		if strings.HasPrefix(opt, name+":") {
+			return opt[len(name)+1:]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,66 @@
+package xlsx
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/gsdocker/gserrors"
+)
+
+// validate check the converted field value against the "min:" and "max:" options
+// of column, the non numeric fields and nil pointers are not checked
+func (reader *RowReader) validate(col *column, field reflect.Value) error {
+
+	lower, upper := col.opts.Get("min"), col.opts.Get("max")
+
+	if lower == "" && upper == "" {
+		return nil
+	}
+
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+
+		field = field.Elem()
+	}
+
+	var val float64
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val = float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val = float64(field.Uint())
+	case reflect.Float32, reflect.Float64:
+		val = field.Float()
+	default:
+		return nil
+	}
+
+	if lower != "" {
+		bound, err := strconv.ParseFloat(lower, 64)
+
+		if err != nil {
+			return gserrors.Newf(err, "invalid min option '%s' of column %s", lower, col.key)
+		}
+
+		if val < bound {
+			return gserrors.Newf(nil, "cell[%s] value %v is less than min %s", reader.cell(col), field, lower)
+		}
+	}
+
+	if upper != "" {
+		bound, err := strconv.ParseFloat(upper, 64)
+
+		if err != nil {
+			return gserrors.Newf(err, "invalid max option '%s' of column %s", upper, col.key)
+		}
+
+		if val > bound {
+			return gserrors.Newf(nil, "cell[%s] value %v is greater than max %s", reader.cell(col), field, upper)
+		}
+	}
+
+	return nil
+}
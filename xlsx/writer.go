@@ -0,0 +1,595 @@
+package xlsx
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gsdocker/gserrors"
+	"github.com/gsdocker/gslogger"
+	x "github.com/tealeg/xlsx"
+)
+
+// MarshalF .
+type MarshalF func(reflect.Value) (string, error)
+
+// ErrInvalidMarshal .
+type ErrInvalidMarshal struct {
+	Type reflect.Type
+}
+
+func (e *ErrInvalidMarshal) Error() string {
+	if e.Type == nil {
+		return "xlsx: Write(nil)"
+	}
+
+	return "xlsx: Write(non-struct slice " + e.Type.String() + ")"
+}
+
+// Writer xlsx writer
+type Writer struct {
+	gslogger.Log                           // mixin log
+	filename     string                    // target file name
+	file         *x.File                   // xlsx file
+	Marshalers   map[string]MarshalF       // marshal functions
+	types        map[reflect.Type]MarshalF // marshal functions by type
+	NameMapping  map[string]string         // name mapping, same as Reader.NameMapping
+	Split        string                    // split chars
+	ExtendHeader bool                      // append the struct columns missing in the existing header, otherwise Append returns error
+	TrueValue    string                    // text of true bool values, e.g. "yes" of Reader.TrueValues, default native excel bool cell
+	FalseValue   string                    // text of false bool values, e.g. "no" of Reader.FalseValues, default native excel bool cell
+	KeySplit     string                    // key value split chars of map cells, same as Reader.KeySplit, default ":"
+	Base64       *base64.Encoding          // encoding of []byte cells, same as Reader.Base64, default base64.StdEncoding
+}
+
+// NewWriter create new xlsx file writer
+func NewWriter(filename string) *Writer {
+	return &Writer{
+		Log:      gslogger.Get("xlsx"),
+		filename: filename,
+		file:     x.NewFile(),
+		Split:    ",",
+	}
+}
+
+// OpenWriter create xlsx writer of existing file for appending rows by Append,
+// the new file is created if filename not exists
+func OpenWriter(filename string) (*Writer, error) {
+
+	writer := NewWriter(filename)
+
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return writer, nil
+	}
+
+	file, err := x.OpenFile(filename)
+
+	if err != nil {
+		return nil, gserrors.Newf(err, "open xlsx file error :%s", filename)
+	}
+
+	writer.file = file
+
+	return writer, nil
+}
+
+// RegisterType register marshal function for all fields and slice elements of type t, the
+// function is called with the field value. the Marshalers keyed by column take precedence
+func (writer *Writer) RegisterType(t reflect.Type, f MarshalF) {
+	if writer.types == nil {
+		writer.types = make(map[reflect.Type]MarshalF)
+	}
+
+	writer.types[t] = f
+}
+
+// Write write rows into new sheet, rows must be a slice of struct or struct pointer
+func (writer *Writer) Write(sheetName string, rows interface{}) error {
+
+	rv, elemType, err := rowsValue(rows)
+
+	if err != nil {
+		return err
+	}
+
+	sheet, err := writer.file.AddSheet(sheetName)
+
+	if err != nil {
+		return gserrors.Newf(err, "create sheet %s error", sheetName)
+	}
+
+	columns := writer.columns(sheetName, elemType)
+
+	writeHeader(sheet, columns)
+
+	return writer.writeRows(sheet, columns, rv)
+}
+
+// Append append rows to sheet, the sheet and header are created if absent. the columns
+// are matched to the existing header by name, the columns missing in header are
+// appended to header with ExtendHeader, otherwise an error is returned
+func (writer *Writer) Append(sheetName string, rows interface{}) error {
+
+	sheet, ok := writer.file.Sheet[sheetName]
+
+	if !ok {
+		return writer.Write(sheetName, rows)
+	}
+
+	rv, elemType, err := rowsValue(rows)
+
+	if err != nil {
+		return err
+	}
+
+	columns := writer.columns(sheetName, elemType)
+
+	if len(sheet.Rows) == 0 {
+		writeHeader(sheet, columns)
+		return writer.writeRows(sheet, columns, rv)
+	}
+
+	header := sheet.Rows[0]
+
+	index := make(map[string]int)
+
+	for i, cell := range header.Cells {
+		if _, ok := index[cell.Value]; !ok {
+			index[cell.Value] = i
+		}
+	}
+
+	var missing []string
+
+	for i := range columns {
+
+		if cell, ok := index[columns[i].header]; ok {
+			columns[i].cell = cell
+			continue
+		}
+
+		if !writer.ExtendHeader {
+			missing = append(missing, columns[i].header)
+			continue
+		}
+
+		header.AddCell().SetString(columns[i].header)
+
+		columns[i].cell = len(header.Cells) - 1
+	}
+
+	if len(missing) != 0 {
+		return gserrors.Newf(nil, "columns %s not found in header of sheet %s", strings.Join(missing, ", "), sheetName)
+	}
+
+	return writer.writeRows(sheet, columns, rv)
+}
+
+// rowsValue check rows is a slice or array of struct or struct pointer
+func rowsValue(rows interface{}) (reflect.Value, reflect.Type, error) {
+
+	rv := reflect.ValueOf(rows)
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return rv, nil, &ErrInvalidMarshal{reflect.TypeOf(rows)}
+	}
+
+	elemType := rv.Type().Elem()
+
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return rv, nil, &ErrInvalidMarshal{reflect.TypeOf(rows)}
+	}
+
+	return rv, elemType, nil
+}
+
+func writeHeader(sheet *x.Sheet, columns []writeColumn) {
+
+	header := sheet.AddRow()
+
+	for _, col := range columns {
+		header.AddCell().SetString(col.header)
+	}
+}
+
+// writeRows write rows after the existing rows of sheet, the cells of row are placed by column cell index
+func (writer *Writer) writeRows(sheet *x.Sheet, columns []writeColumn, rv reflect.Value) error {
+
+	width := 0
+
+	for _, col := range columns {
+		if col.cell >= width {
+			width = col.cell + 1
+		}
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+
+		elem := rv.Index(i)
+
+		row := sheet.AddRow()
+
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+
+			elem = elem.Elem()
+		}
+
+		for j := 0; j < width; j++ {
+			row.AddCell()
+		}
+
+		for _, col := range columns {
+
+			cell := row.Cells[col.cell]
+
+			field, ok := writeField(elem, col.field)
+
+			// the cells of nil nested struct pointer are left empty
+			if !ok {
+				continue
+			}
+
+			if writer.Marshalers != nil {
+				if f, ok := writer.Marshalers[col.key]; ok {
+
+					val, err := f(field)
+
+					if err != nil {
+						return gserrors.Newf(err, "can't conv cell[%s:%d]", col.header, i)
+					}
+
+					cell.SetString(val)
+
+					continue
+				}
+			}
+
+			if err := writer.writeBuiltinType(col.key, i, cell, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type writeColumn struct {
+	header string // column header
+	key    string // marshaler key
+	field  []int  // struct field index path
+	cell   int    // cell index of row
+}
+
+// writeField get the field of struct value by index path, return false if the path
+// walks through a nil pointer of nested struct
+func writeField(v reflect.Value, index []int) (reflect.Value, bool) {
+
+	for i, n := range index {
+
+		for i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(n)
+	}
+
+	return v, true
+}
+
+func (writer *Writer) columns(sheetName string, structType reflect.Type) (columns []writeColumn) {
+
+	reverse := make(map[string]string)
+
+	prefix := sheetName + "."
+
+	for key, name := range writer.NameMapping {
+		if strings.HasPrefix(key, prefix) {
+			reverse[name] = strings.TrimPrefix(key, prefix)
+		}
+	}
+
+	writer.structColumns(sheetName, structType, "", "", nil, reverse, &columns)
+
+	return
+}
+
+// structColumns append the columns of struct fields, the nested struct fields are flattened
+// into dotted headers like "Address.City" which can be read by Reader, the fields of
+// embedded structs are promoted without prefix
+func (writer *Writer) structColumns(sheetName string, structType reflect.Type, header, key string, index []int, reverse map[string]string, columns *[]writeColumn) {
+
+	for i := 0; i < structType.NumField(); i++ {
+
+		field := structType.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, _ := fieldTag(field)
+
+		if name == "-" {
+			continue
+		}
+
+		fieldHeader := field.Name
+
+		// the promoted fields of embedded structs are top level columns like the Reader
+		if mapped, ok := reverse[field.Name]; ok && header == "" {
+			fieldHeader = mapped
+		}
+
+		if name != "" {
+			fieldHeader = name
+		}
+
+		fieldKey := fmt.Sprintf("%s.%s", sheetName, key+field.Name)
+
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if writer.nested(field.Type, fieldKey) {
+
+			if field.Anonymous && name == "" {
+				writer.structColumns(sheetName, indirectType(field.Type), header, key, fieldIndex, reverse, columns)
+			} else {
+				writer.structColumns(sheetName, indirectType(field.Type), header+fieldHeader+".", key+field.Name+".", fieldIndex, reverse, columns)
+			}
+
+			continue
+		}
+
+		*columns = append(*columns, writeColumn{
+			header: header + fieldHeader,
+			key:    fieldKey,
+			field:  fieldIndex,
+			cell:   len(*columns),
+		})
+	}
+}
+
+// nested check if the field of type t is a nested struct flattened into columns, the
+// struct with marshaler and time.Time are written as one cell
+func (writer *Writer) nested(t reflect.Type, key string) bool {
+
+	if _, ok := writer.Marshalers[key]; ok {
+		return false
+	}
+
+	if _, ok := writer.types[t]; ok {
+		return false
+	}
+
+	t = indirectType(t)
+
+	if _, ok := writer.types[t]; ok {
+		return false
+	}
+
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return false
+	}
+
+	return t.Kind() == reflect.Struct && t != timeType && t != urlType
+}
+
+func (writer *Writer) writeBuiltinType(colname string, id int, cell *x.Cell, val reflect.Value) error {
+
+	if f, ok := writer.types[val.Type()]; ok {
+
+		s, err := f(val)
+
+		if err != nil {
+			return gserrors.Newf(err, "can't conv cell[%s:%d]", colname, id)
+		}
+
+		cell.SetString(s)
+
+		return nil
+	}
+
+	switch val.Type() {
+	case timeType:
+		// the zero time is left empty, which is read as zero time. SetDateTime truncates to
+		// seconds, the serial date keeps the milliseconds read by Reader
+		if t := val.Interface().(time.Time); !t.IsZero() {
+			cell.SetDateTimeWithFormat(x.TimeToExcelTime(t.UTC(), writer.file.Date1904), x.DefaultDateTimeOptions.ExcelTimeFormat)
+		}
+
+		return nil
+	case durationType:
+		cell.SetString(time.Duration(val.Int()).String())
+		return nil
+	case urlType:
+		u := val.Interface().(url.URL)
+		cell.SetString(u.String())
+		return nil
+	}
+
+	// the pointers are marshaled by the element, so the nil pointers are left empty
+	if marshaler, ok := textMarshaler(val); ok && val.Kind() != reflect.Ptr {
+
+		text, err := marshaler.MarshalText()
+
+		if err != nil {
+			return gserrors.Newf(err, "can't conv cell[%s:%d]", colname, id)
+		}
+
+		cell.SetString(string(text))
+
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.Bool:
+		writer.writeBool(cell, val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		cell.SetInt64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// the uints beyond the exact integers of excel double are written as text
+		if v := val.Uint(); v <= maxExactInt {
+			cell.SetInt64(int64(v))
+		} else {
+			cell.SetString(strconv.FormatUint(v, 10))
+		}
+	case reflect.Float32, reflect.Float64:
+
+		if v := val.Float(); math.IsNaN(v) || math.IsInf(v, 0) {
+			return gserrors.Newf(nil, "can't conv cell[%s:%d], non-finite number %v", colname, id, v)
+		}
+
+		if val.Kind() == reflect.Float32 {
+			cell.SetValue(float32(val.Float()))
+		} else {
+			cell.SetFloat(val.Float())
+		}
+	case reflect.String:
+		cell.SetString(val.String())
+	case reflect.Slice, reflect.Array:
+
+		if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 {
+			writer.writeBytes(cell, val.Bytes())
+			return nil
+		}
+
+		subs := make([]string, val.Len())
+
+		for i := 0; i < val.Len(); i++ {
+
+			sub := &x.Cell{}
+
+			if err := writer.writeBuiltinType(colname, id, sub, val.Index(i)); err != nil {
+				return err
+			}
+
+			subs[i] = sub.Value
+		}
+
+		cell.SetString(strings.Join(subs, writer.Split))
+
+	case reflect.Map:
+		return writer.writeMap(colname, id, cell, val)
+
+	case reflect.Ptr:
+		if val.IsNil() {
+			return nil
+		}
+
+		return writer.writeBuiltinType(colname, id, cell, val.Elem())
+
+	default:
+		return gserrors.Newf(nil, "can't conv cell[%s:%d], not found marshaler for type %s", colname, id, val.Type())
+	}
+
+	return nil
+}
+
+// maxExactInt the max integer represented exactly by excel double, 2^53
+const maxExactInt = 1 << 53
+
+// textMarshaler get the encoding.TextMarshaler of value or pointer to value
+func textMarshaler(val reflect.Value) (encoding.TextMarshaler, bool) {
+
+	if val.Type().Implements(textMarshalerType) {
+		marshaler, ok := val.Interface().(encoding.TextMarshaler)
+		return marshaler, ok
+	}
+
+	if reflect.PointerTo(val.Type()).Implements(textMarshalerType) {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		return ptr.Interface().(encoding.TextMarshaler), true
+	}
+
+	return nil, false
+}
+
+// writeBytes write []byte as base64 text, which is decoded by Reader
+func (writer *Writer) writeBytes(cell *x.Cell, data []byte) {
+
+	encoding := writer.Base64
+
+	if encoding == nil {
+		encoding = base64.StdEncoding
+	}
+
+	cell.SetString(encoding.EncodeToString(data))
+}
+
+// writeMap write map as key value pairs like "k1:v1,k2:v2" sorted by key, which is read by Reader
+func (writer *Writer) writeMap(colname string, id int, cell *x.Cell, val reflect.Value) error {
+
+	keySplit := writer.KeySplit
+
+	if keySplit == "" {
+		keySplit = ":"
+	}
+
+	pairs := make([]string, 0, val.Len())
+
+	for _, key := range val.MapKeys() {
+
+		k, v := &x.Cell{}, &x.Cell{}
+
+		if err := writer.writeBuiltinType(colname, id, k, key); err != nil {
+			return err
+		}
+
+		if err := writer.writeBuiltinType(colname, id, v, val.MapIndex(key)); err != nil {
+			return err
+		}
+
+		pairs = append(pairs, k.Value+keySplit+v.Value)
+	}
+
+	sort.Strings(pairs)
+
+	cell.SetString(strings.Join(pairs, writer.Split))
+
+	return nil
+}
+
+// writeBool write bool as native excel bool cell, or the TrueValue and FalseValue text if set
+func (writer *Writer) writeBool(cell *x.Cell, val bool) {
+
+	switch {
+	case val && writer.TrueValue != "":
+		cell.SetString(writer.TrueValue)
+	case !val && writer.FalseValue != "":
+		cell.SetString(writer.FalseValue)
+	default:
+		cell.SetBool(val)
+	}
+}
+
+// Save flush the xlsx file to disk
+func (writer *Writer) Save() error {
+	if err := writer.file.Save(writer.filename); err != nil {
+		return gserrors.Newf(err, "save xlsx file error :%s", writer.filename)
+	}
+
+	return nil
+}
+
+// Close save the xlsx file, the writer can't be used after close
+func (writer *Writer) Close() error {
+	return writer.Save()
+}
@@ -0,0 +1,509 @@
+package xlsx
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	x "github.com/tealeg/xlsx"
+)
+
+type recordRow struct {
+	Name  string
+	Count int
+}
+
+type extendedRecordRow struct {
+	Count int
+	Name  string
+	Score float64
+}
+
+func TestWriterAppend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "append.xlsx")
+
+	writer, err := OpenWriter(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Append("Sheet1", []recordRow{{"a", 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	writer, err = OpenWriter(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Append("Sheet1", []*recordRow{{"b", 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Append("Sheet1", []extendedRecordRow{{3, "c", 1.5}}); err == nil || !strings.Contains(err.Error(), "Score") {
+		t.Fatalf("expect missing header column error, got %v", err)
+	}
+
+	writer.ExtendHeader = true
+
+	if err := writer.Append("Sheet1", []extendedRecordRow{{3, "c", 1.5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Append("Sheet2", []recordRow{{"d", 4}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vals, err := ReadAll[extendedRecordRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []extendedRecordRow{{1, "a", 0}, {2, "b", 0}, {3, "c", 1.5}}
+
+	if !reflect.DeepEqual(vals, expect) {
+		t.Fatalf("unexpected rows: %#v", vals)
+	}
+
+	records, err := ReadAll[recordRow](reader, "Sheet2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records, []recordRow{{"d", 4}}) {
+		t.Fatalf("unexpected rows of new sheet: %#v", records)
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "roundtrip.xlsx")
+
+	writer := NewWriter(filename)
+
+	writer.RegisterType(reflect.TypeOf(color{}), func(val reflect.Value) (string, error) {
+		c := val.Interface().(color)
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), nil
+	})
+
+	writer.Split = ";"
+
+	rows := []colorRow{
+		{color{1, 2, 3}, color{4, 5, 6}, []color{{7, 8, 9}, {10, 11, 12}}},
+		{color{255, 0, 0}, color{0, 0, 0}, nil},
+	}
+
+	if err := writer.Write("Sheet1", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader.RegisterType(reflect.TypeOf(color{}), func(val reflect.Value, cell string) error {
+		var c color
+
+		if _, err := fmt.Sscanf(cell, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+			return err
+		}
+
+		val.Set(reflect.ValueOf(c))
+
+		return nil
+	})
+
+	reader.Splits = map[string]string{"Sheet1.Palette": ";"}
+
+	vals, err := ReadAll[colorRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows[1].Palette = []color{}
+
+	if !reflect.DeepEqual(vals, rows) {
+		t.Fatalf("unexpected round trip rows: %#v", vals)
+	}
+}
+
+func TestWriterNameMapping(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "mapping.xlsx")
+
+	writer := NewWriter(filename)
+
+	mapping := map[string]string{
+		"Sheet1.Full Name": "Name",
+		"Sheet1.Total":     "Count",
+		"Sheet2.Other":     "Name",
+	}
+
+	writer.NameMapping = mapping
+
+	rows := []recordRow{{"a", 1}, {"b", 2}}
+
+	if err := writer.Write("Sheet1", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the headers are reverse mapped, the mapping of other sheets is ignored
+	if header := reader.Read("Sheet1")[0].Columns(); !reflect.DeepEqual(header, []string{"Full Name", "Total"}) {
+		t.Fatalf("unexpected header: %v", header)
+	}
+
+	reader.NameMapping = mapping
+
+	vals, err := ReadAll[recordRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(vals, rows) {
+		t.Fatalf("unexpected round trip rows: %v", vals)
+	}
+}
+
+type mappedRow struct {
+	Base
+	Count int
+}
+
+func TestWriterNameMappingEmbedded(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "embedded.xlsx")
+
+	writer := NewWriter(filename)
+
+	writer.NameMapping = map[string]string{
+		"Sheet1.Identifier": "ID",
+	}
+
+	if err := writer.Write("Sheet1", []mappedRow{{Base{1, "a"}, 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the promoted fields are reverse mapped like the top level fields
+	if header := reader.Read("Sheet1")[0].Columns(); !reflect.DeepEqual(header, []string{"Identifier", "Type", "Count"}) {
+		t.Fatalf("unexpected header: %v", header)
+	}
+}
+
+type numberRow struct {
+	Count uint
+	Big   uint64
+	Ratio float32
+}
+
+func TestWriterNumbers(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "numbers.xlsx")
+
+	writer := NewWriter(filename)
+
+	rows := []numberRow{{3, 1 << 60, 0.1}}
+
+	if err := writer.Write("Sheet1", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Write("Sheet2", []numberRow{{Ratio: float32(math.NaN())}}); err == nil || !strings.Contains(err.Error(), "non-finite") {
+		t.Fatalf("expect non-finite number error, got %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cells := reader.Sheet("Sheet1").Rows[1].Cells
+
+	if cells[0].Type() != x.CellTypeNumeric || cells[1].Type() != x.CellTypeString || cells[2].Value != "0.1" {
+		t.Fatalf("unexpected cells: %v %v %v", cells[0].Type(), cells[1].Type(), cells[2].Value)
+	}
+
+	vals, err := ReadAll[numberRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(vals, rows) {
+		t.Fatalf("unexpected round trip rows: %v", vals)
+	}
+}
+
+func TestWriterIndexTag(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "index.xlsx")
+
+	writer := NewWriter(filename)
+
+	if err := writer.Write("Sheet1", []indexRow{{"a", 1.5, 30, "x"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header := reader.Read("Sheet1")[0].Columns(); !reflect.DeepEqual(header, []string{"Name", "Score", "Age", "Note"}) {
+		t.Fatalf("expect field names as headers, got %v", header)
+	}
+}
+
+func TestWriterSkipField(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "skip.xlsx")
+
+	writer := NewWriter(filename)
+
+	if err := writer.Write("Sheet1", []skipRow{{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := reader.Read("Sheet1")[0]
+
+	if header := row.Columns(); !reflect.DeepEqual(header, []string{"Name"}) {
+		t.Fatalf("unexpected header: %v", header)
+	}
+
+	var val skipRow
+
+	if err := row.Read(&val); err != nil || val != (skipRow{Name: "a"}) {
+		t.Fatalf("unexpected row: %v %v", val, err)
+	}
+}
+
+func TestWriterTagOverNameMapping(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "precedence.xlsx")
+
+	writer := NewWriter(filename)
+
+	writer.NameMapping = map[string]string{
+		"Sheet1.Alias": "Name",
+	}
+
+	if err := writer.Write("Sheet1", []precedenceRow{{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header := reader.Read("Sheet1")[0].Columns(); !reflect.DeepEqual(header, []string{"Full Name", "Nick"}) {
+		t.Fatalf("expect tag wins over name mapping, got header %v", header)
+	}
+}
+
+type flagRow struct {
+	Name   string
+	Active bool
+	Flags  []bool
+}
+
+func TestWriterBoolRoundTrip(t *testing.T) {
+	rows := []flagRow{
+		{"a", true, []bool{true, false}},
+		{"b", false, []bool{false}},
+	}
+
+	for _, tokens := range [][2]string{{"", ""}, {"yes", "no"}} {
+
+		filename := filepath.Join(t.TempDir(), "bool.xlsx")
+
+		writer := NewWriter(filename)
+
+		writer.TrueValue, writer.FalseValue = tokens[0], tokens[1]
+
+		if err := writer.Write("Sheet1", rows); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		reader, err := NewReader(filename)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tokens[0] != "" {
+			reader.TrueValues = []string{tokens[0]}
+			reader.FalseValues = []string{tokens[1]}
+		} else if cell := reader.file.Sheet["Sheet1"].Rows[1].Cells[1]; cell.Type() != x.CellTypeBool {
+			t.Fatalf("expect native bool cell, got %v", cell.Type())
+		}
+
+		vals, err := ReadAll[flagRow](reader, "Sheet1")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(vals, rows) {
+			t.Fatalf("unexpected round trip rows of %v: %#v", tokens, vals)
+		}
+	}
+}
+
+type profileRow struct {
+	Base
+	Name    string
+	Score   float64
+	Active  bool
+	Tags    []int
+	Address address
+	Office  *address
+	Joined  time.Time
+	Left    *time.Time
+	Timeout time.Duration
+	Avatar  []byte
+	Quotas  map[string]int
+	Level   level
+	Levels  []level
+	IP      net.IP
+	Home    url.URL
+}
+
+func (l level) MarshalText() ([]byte, error) {
+	switch l {
+	case 1:
+		return []byte("low"), nil
+	case 2:
+		return []byte("high"), nil
+	}
+
+	return nil, fmt.Errorf("invalid level %d", l)
+}
+
+func TestWriterNestedRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "nested.xlsx")
+
+	writer := NewWriter(filename)
+
+	joined := time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	// the sub-second time is kept to milliseconds
+	left := time.Date(2021, 1, 2, 3, 4, 5, 250*int(time.Millisecond), time.UTC)
+
+	home, _ := url.Parse("https://example.com/a?b=c")
+
+	rows := []profileRow{
+		{Base{1, "a"}, "alice", 1.5, true, []int{1, 2}, address{"Paris", "75001", &geo{48.8, 2.3}}, &address{City: "Lyon"},
+			joined, &left, 90 * time.Minute, []byte("hi,\x00there"), map[string]int{"disk": 10, "cpu": 2}, 2, []level{1, 2}, net.ParseIP("10.0.0.1"), *home},
+		{Base{2, "b"}, "bob", 0, false, []int{3}, address{City: "Rome"}, nil,
+			time.Time{}, nil, 0, []byte{}, map[string]int{}, 1, []level{}, nil, url.URL{}},
+	}
+
+	if err := writer.Write("Sheet1", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader.DisallowUnknownColumns = true
+
+	header := reader.Read("Sheet1")[0].Columns()
+
+	expect := []string{"ID", "Type", "Name", "Score", "Active", "Tags", "Address.City", "Address.Post Code", "Address.Geo.Lat", "Address.Geo.Lng",
+		"Office.City", "Office.Post Code", "Office.Geo.Lat", "Office.Geo.Lng", "Joined", "Left", "Timeout", "Avatar", "Quotas", "Level", "Levels", "IP", "Home"}
+
+	if !reflect.DeepEqual(header, expect) {
+		t.Fatalf("unexpected header: %v", header)
+	}
+
+	vals, err := ReadAll[profileRow](reader, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(vals, rows) {
+		t.Fatalf("unexpected round trip rows: %#v", vals)
+	}
+}